@@ -0,0 +1,161 @@
+package errors
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const packagePath = "github.com/fredbi/wrappable-errors"
+
+// Deprecation reports the use of a deprecated sentinel error, declared with Deprecate
+type Deprecation struct {
+	// Sentinel is the deprecated sentinel
+	Sentinel error
+
+	// Replacement is the sentinel to be used instead
+	Replacement error
+
+	// Caller is the location of the code which used the deprecated sentinel
+	Caller Frame
+}
+
+// deprecations of sentinel errors, indexed by deprecated sentinel
+var deprecations = struct {
+	sync.RWMutex
+
+	active       atomic.Bool
+	replacements map[error]error
+	hook         func(Deprecation)
+	reported     map[deprecationSite]struct{}
+}{
+	replacements: make(map[error]error),
+	reported:     make(map[deprecationSite]struct{}),
+}
+
+type deprecationSite struct {
+	pc       uintptr
+	sentinel error
+}
+
+// Alias declares two sentinel errors as equivalent: errors which are one of them match the other with Is.
+//
+// This is useful to rename or merge sentinels while callers still check for the former one.
+//
+// An error wrapping ErrInvalidHierarchy is returned if a sentinel is nil or not comparable, or if both are the same.
+func Alias(old, new error) error {
+	if !isComparableError(old) || !isComparableError(new) {
+		return ErrInvalidHierarchy.Errorf("aliases must be non-nil comparable errors, got %T and %T", old, new)
+	}
+
+	if sameSentinel(old, new) {
+		return ErrInvalidHierarchy.Errorf("cannot alias %q to itself", old)
+	}
+
+	hierarchy.Lock()
+	defer hierarchy.Unlock()
+
+	link(hierarchy.aliases, old, new)
+	link(hierarchy.aliases, new, old)
+
+	return nil
+}
+
+// Deprecate declares a sentinel error as deprecated in favor of a replacement.
+//
+// Like with Alias, the deprecated sentinel and its replacement are equivalent with Is.
+// When a deprecation hook is set with SetDeprecationHook, it is called when the deprecated sentinel
+// is matched with Is or used to build other errors.
+func Deprecate(old, replacement error) error {
+	if err := Alias(old, replacement); err != nil {
+		return err
+	}
+
+	deprecations.Lock()
+	defer deprecations.Unlock()
+
+	deprecations.replacements[old] = replacement
+	if head, ok := ownedHead(old); ok {
+		deprecations.replacements[head] = replacement
+	}
+	deprecations.active.Store(deprecations.hook != nil)
+
+	return nil
+}
+
+// SetDeprecationHook sets a function called when deprecated sentinels are used.
+//
+// The hook is called at most once per call site and deprecated sentinel, when:
+//   - the deprecated sentinel is the target of Is, either from this package or from the standard library
+//   - the deprecated sentinel is used to build other errors, e.g. with Wrap(), Errorf() or With()
+//
+// A nil hook disables reporting. Setting a hook resets the call sites already reported.
+func SetDeprecationHook(hook func(Deprecation)) {
+	deprecations.Lock()
+	defer deprecations.Unlock()
+
+	deprecations.hook = hook
+	deprecations.reported = make(map[deprecationSite]struct{})
+	deprecations.active.Store(hook != nil && len(deprecations.replacements) > 0)
+}
+
+// reportDeprecated calls the deprecation hook if the sentinel is deprecated
+func reportDeprecated(sentinel error) {
+	if !deprecations.active.Load() || !isComparableError(sentinel) {
+		return
+	}
+
+	deprecations.RLock()
+	replacement, isDeprecated := deprecations.replacements[sentinel]
+	deprecations.RUnlock()
+
+	if !isDeprecated {
+		return
+	}
+
+	pc, frame := callSite()
+	site := deprecationSite{pc: pc, sentinel: sentinel}
+
+	deprecations.Lock()
+	if _, reported := deprecations.reported[site]; reported {
+		deprecations.Unlock()
+
+		return
+	}
+	deprecations.reported[site] = struct{}{}
+	hook := deprecations.hook
+	deprecations.Unlock()
+
+	if hook != nil {
+		hook(Deprecation{Sentinel: sentinel, Replacement: replacement, Caller: frame})
+	}
+}
+
+// callSite returns the location of the first caller outside this package and the standard errors package
+func callSite() (uintptr, Frame) {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+		if !isInternalFrame(frame) {
+			return frame.PC, Frame{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+
+		if !more {
+			return frame.PC, Frame{Function: frame.Function, File: frame.File, Line: frame.Line}
+		}
+	}
+}
+
+func isInternalFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+
+	return strings.HasPrefix(frame.Function, packagePath+".") ||
+		strings.HasPrefix(frame.Function, "errors.") ||
+		strings.HasPrefix(frame.Function, "runtime.")
+}
@@ -0,0 +1,108 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlias(t *testing.T) {
+	errOld := New("old")
+	errNew := New("new")
+	errParent := New("parent")
+	errPlain := stderrors.New("plain")
+
+	require.NoError(t, Alias(errOld, errNew))
+	require.NoError(t, Alias(errPlain, io.ErrClosedPipe))
+	require.NoError(t, DeclareParent(errNew, errParent))
+
+	assert.True(t, Is(errOld, errNew))
+	assert.True(t, Is(errNew, errOld))
+	assert.True(t, Is(errOld.Wrap(io.EOF), errNew))
+	assert.True(t, Is(New("get").Wrap(errNew.Errorf("id %d", 42)), errOld))
+	assert.True(t, stderrors.Is(errNew.Wrap(io.EOF), errOld))
+	assert.True(t, Is(errOld, errParent), "aliases should inherit parents")
+	assert.False(t, Is(errParent, errOld))
+	assert.True(t, Is(fmt.Errorf("wrapped: %w", errPlain), io.ErrClosedPipe))
+	assert.True(t, Is(fmt.Errorf("wrapped: %w", io.ErrClosedPipe), errPlain))
+
+	var handled bool
+	Match(errNew).Is(errOld, func(error) { handled = true }).Dispatch()
+	assert.True(t, handled)
+
+	t.Run("should reject invalid aliases", func(t *testing.T) {
+		require.ErrorIs(t, Alias(nil, errNew), ErrInvalidHierarchy)
+		require.ErrorIs(t, Alias(errOld, uncomparableError{}), ErrInvalidHierarchy)
+		require.ErrorIs(t, Alias(errOld, errOld), ErrInvalidHierarchy)
+		require.ErrorIs(t, Deprecate(errOld, nil), ErrInvalidHierarchy)
+	})
+
+	t.Run("should detect cycles through aliases", func(t *testing.T) {
+		require.ErrorIs(t, DeclareParent(errParent, errOld), ErrInvalidHierarchy)
+	})
+}
+
+func TestDeprecate(t *testing.T) {
+	errLegacy := New("legacy")
+	errCurrent := New("current")
+	errPlain := stderrors.New("plain")
+	errLegacyPlain := NewErr(errPlain)
+	require.NoError(t, Deprecate(errLegacy, errCurrent))
+	require.NoError(t, Deprecate(errLegacyPlain, errCurrent))
+
+	var reports []Deprecation
+	SetDeprecationHook(func(d Deprecation) {
+		reports = append(reports, d)
+	})
+	defer SetDeprecationHook(nil)
+
+	for range 3 {
+		assert.True(t, Is(errCurrent.Wrap(io.EOF), errLegacy)) // reported once for this call site
+	}
+	require.Len(t, reports, 1)
+	assert.Equal(t, errLegacy, reports[0].Sentinel)
+	assert.Equal(t, errCurrent, reports[0].Replacement)
+	assert.Equal(t, "alias_test.go", filepath.Base(reports[0].Caller.File))
+	assert.Contains(t, reports[0].Caller.Function, "TestDeprecate")
+
+	assert.True(t, stderrors.Is(New("get").Wrap(errCurrent), errLegacy))
+	require.Len(t, reports, 2)
+	assert.NotEqual(t, reports[0].Caller.Line, reports[1].Caller.Line)
+
+	_ = errLegacy.Wrap(io.EOF)
+	require.Len(t, reports, 3)
+	_ = errLegacy.Errorf("id %d", 42)
+	require.Len(t, reports, 4)
+	_ = errLegacy.With("id", 42)
+	require.Len(t, reports, 5)
+
+	// errors which are not deprecated are not reported
+	assert.True(t, Is(errCurrent, errCurrent))
+	_ = errCurrent.Wrap(io.EOF)
+	require.Len(t, reports, 5)
+
+	// the error wrapped by a deprecated sentinel is neither deprecated nor an alias
+	assert.False(t, Is(errPlain, errCurrent))
+	assert.False(t, Is(fmt.Errorf("wrapped: %w", io.EOF), errPlain))
+	_ = New("get").Wrap(errPlain)
+	require.Len(t, reports, 5)
+
+	t.Run("should reset reports with a new hook", func(t *testing.T) {
+		var count int
+		SetDeprecationHook(func(Deprecation) { count++ })
+
+		for range 2 {
+			_ = errLegacy.Wrap(io.EOF)
+		}
+		assert.Equal(t, 1, count)
+
+		SetDeprecationHook(nil)
+		_ = errLegacy.Wrap(io.EOF)
+		assert.Equal(t, 1, count)
+	})
+}
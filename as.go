@@ -0,0 +1,131 @@
+package errors
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrInvalidTarget is returned by TryAs when the target is not a non-nil pointer to an interface or to a type implementing error.
+var ErrInvalidTarget = New("wrappable-errors: invalid target")
+
+// AsType finds the first error in the chain or tree of errors that is of type T, and returns it.
+//
+// The errors are explored in the same order as with Walk(). Errors with a custom As(interface{}) bool method
+// are asked to convert themselves to T, like with errors.As().
+//
+// Unlike As, AsType cannot panic. Since errors.As() panics when T is neither an interface nor implements error,
+// errors with a custom As method are only asked to convert themselves to such types. AsType does not use reflection,
+// but such custom As methods may.
+func AsType[T any](err error) (T, bool) {
+	var zero T
+	if err == nil {
+		return zero, false
+	}
+
+	return asType[T](err)
+}
+
+// TryAs behaves like As, but returns ErrInvalidTarget when the target is not valid instead of panicking.
+func TryAs(err error, target interface{}) (bool, error) {
+	if target == nil {
+		return false, ErrInvalidTarget.Errorf("target must be a non-nil pointer")
+	}
+
+	val := reflect.ValueOf(target)
+	typ := val.Type()
+	if typ.Kind() != reflect.Ptr || val.IsNil() {
+		return false, ErrInvalidTarget.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+
+	if elem := typ.Elem(); elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		return false, ErrInvalidTarget.Errorf("*target must be interface or implement error, got %T", target)
+	}
+
+	return errors.As(err, target), nil
+}
+
+func asType[T any](err error) (T, bool) {
+	if target, ok := err.(T); ok {
+		return target, true
+	}
+
+	var zero T
+
+	switch e := err.(type) {
+	case *wrapped:
+		if target, ok := asType[T](e.err); ok {
+			return target, true
+		}
+
+		if e.cause == nil {
+			return zero, false
+		}
+
+		return asType[T](e.cause)
+	case *chain:
+		return asTypeBranches[T](e.errs[e.start:])
+	case *joined:
+		return asTypeBranches[T](e.errs)
+	case *stacked:
+		return asType[T](e.Wrappable)
+	case interface{ As(interface{}) bool }:
+		var target T
+		if isAsTarget[T]() && e.As(&target) {
+			return target, true
+		}
+	}
+
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return asTypeBranches[T](e.Unwrap())
+	case headTail:
+		// the head may be explored twice when it is also the tail: this is harmless
+		head, tail := e.Err(), e.Unwrap()
+		if head != nil {
+			if target, ok := asType[T](head); ok {
+				return target, true
+			}
+		}
+
+		if tail == nil {
+			return zero, false
+		}
+
+		return asType[T](tail)
+	case interface{ Unwrap() error }:
+		if tail := e.Unwrap(); tail != nil {
+			return asType[T](tail)
+		}
+	}
+
+	return zero, false
+}
+
+// isAsTarget tells if T is an interface or implements error, like errors.As requires from its target
+func isAsTarget[T any]() bool {
+	var zero T
+	if any(zero) == nil {
+		// T is an interface
+		return true
+	}
+
+	_, ok := any(zero).(error)
+
+	return ok
+}
+
+func asTypeBranches[T any](errs []error) (T, bool) {
+	for _, inner := range errs {
+		if inner == nil {
+			continue
+		}
+
+		if target, ok := asType[T](inner); ok {
+			return target, true
+		}
+	}
+
+	var zero T
+
+	return zero, false
+}
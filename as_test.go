@@ -0,0 +1,187 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type asTestError struct {
+	code int
+}
+
+func (e *asTestError) Error() string {
+	return fmt.Sprintf("code %d", e.code)
+}
+
+// asConverter converts itself to *asTestError
+type asConverter struct{}
+
+func (asConverter) Error() string {
+	return "converter"
+}
+
+func (asConverter) As(target interface{}) bool {
+	if t, ok := target.(**asTestError); ok {
+		*t = &asTestError{code: 99}
+
+		return true
+	}
+
+	return false
+}
+
+// asDelegate delegates its As method to errors.As
+type asDelegate struct {
+	err error
+}
+
+func (e asDelegate) Error() string {
+	return e.err.Error()
+}
+
+func (e asDelegate) As(target interface{}) bool {
+	return stderrors.As(e.err, target)
+}
+
+func TestAsType(t *testing.T) {
+	t.Parallel()
+
+	_, ok := AsType[*asTestError](nil)
+	assert.False(t, ok)
+
+	_, ok = AsType[*asTestError](io.EOF)
+	assert.False(t, ok)
+
+	e := New(str).Wrap(io.EOF).Wrap(fmt.Errorf("message: %w", &asTestError{code: 1})).Wrap(&asTestError{code: 2})
+
+	target, ok := AsType[*asTestError](e)
+	require.True(t, ok)
+	assert.Equal(t, 1, target.code)
+
+	value, ok := AsType[myError](New(str).Wrap(Join(io.EOF, myError("joined"))))
+	require.True(t, ok)
+	assert.Equal(t, myError("joined"), value)
+
+	value, ok = AsType[myError](NewErr(stderrors.Join(io.EOF, fmt.Errorf("multi: %w, %w", io.EOF, myError("tree")))))
+	require.True(t, ok)
+	assert.Equal(t, myError("tree"), value)
+
+	_, ok = AsType[myError](e)
+	assert.False(t, ok)
+
+	// the topmost errors are explored first
+	value, ok = AsType[myError](NewErr(NewErr(myError("head")).Wrap(myError("tail"))).Wrap(myError("cause")))
+	require.True(t, ok)
+	assert.Equal(t, myError("head"), value)
+
+	// interface types
+	wrapper, ok := AsType[Wrappable](fmt.Errorf("outer: %w", e))
+	require.True(t, ok)
+	assert.Equal(t, e, wrapper)
+
+	traceable, ok := AsType[Traceable](New(str).Wrap(WithStack(io.EOF)))
+	require.True(t, ok)
+	assert.NotEmpty(t, traceable.StackTrace())
+
+	// custom As methods
+	target, ok = AsType[*asTestError](New(str).Wrap(asConverter{}))
+	require.True(t, ok)
+	assert.Equal(t, 99, target.code)
+
+	// custom As methods are not called with targets which errors.As rejects
+	delegate := asDelegate{err: &asTestError{code: 3}}
+	require.NotPanics(t, func() {
+		_, ok = AsType[int](fmt.Errorf("outer: %w", delegate))
+	})
+	assert.False(t, ok)
+
+	target, ok = AsType[*asTestError](fmt.Errorf("outer: %w", delegate))
+	require.True(t, ok)
+	assert.Equal(t, 3, target.code)
+
+	wrapper, ok = AsType[Wrappable](asDelegate{err: e})
+	require.True(t, ok)
+	assert.Equal(t, e, wrapper)
+
+	// custom error classes
+	pathErr, ok := AsType[*fs.PathError](New(str).Wrap(&fs.PathError{Op: "open", Path: "/etc", Err: fs.ErrNotExist}))
+	require.True(t, ok)
+	assert.Equal(t, "/etc", pathErr.Path)
+
+	// same result as As
+	var expected *asTestError
+	require.True(t, As(e, &expected))
+	target, _ = AsType[*asTestError](e)
+	assert.Equal(t, expected, target)
+}
+
+func TestTryAs(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(&asTestError{code: 1})
+
+	var target *asTestError
+	ok, err := TryAs(e, &target)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, 1, target.code)
+
+	var other myError
+	ok, err = TryAs(e, &other)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, invalid := range []interface{}{
+		nil,
+		target,
+		(*myError)(nil),
+		new(int),
+	} {
+		ok, err = TryAs(e, invalid)
+		assert.False(t, ok)
+		require.Error(t, err)
+		assert.True(t, Is(err, ErrInvalidTarget))
+	}
+}
+
+func longChain(depth int, tail error) error {
+	e := New(str)
+	for i := 0; i < depth; i++ {
+		e = e.Wrap(fmt.Errorf("layer %d: %w", i, io.EOF))
+	}
+
+	return e.Wrap(tail)
+}
+
+func BenchmarkAs(b *testing.B) {
+	for _, depth := range []int{10, 100} {
+		e := longChain(depth, &asTestError{code: 1})
+
+		b.Run(fmt.Sprintf("As-%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				var target *asTestError
+				if !As(e, &target) {
+					b.Fatal("expected a match")
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("AsType-%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, ok := AsType[*asTestError](e); !ok {
+					b.Fatal("expected a match")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+const badKey = "!BADKEY"
+
+// Attr is a key/value pair attached to an error
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// String representation of an attribute, as "key=value"
+func (a Attr) String() string {
+	return fmt.Sprintf("%s=%v", a.Key, a.Value)
+}
+
+// Attributer is an error which carries attributes.
+//
+// Custom error types may implement this interface to contribute attributes with Attributes().
+type Attributer interface {
+	Attributes() []Attr
+}
+
+var (
+	_ Attributer = &wrapped{}
+	_ Attributer = &stacked{}
+)
+
+// Attributes collects the attributes attached to all the errors in a chain or tree of errors.
+//
+// When the same key is found several times, the outermost error takes precedence. Within the same error,
+// the last value set with With() takes precedence.
+//
+// Attributes are returned in the order they were first found, starting with the outermost error.
+func Attributes(err error) []Attr {
+	var (
+		attrs []Attr
+		index map[string]int
+	)
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		attributer, ok := node.(Attributer)
+		if !ok {
+			return WalkContinue
+		}
+
+		layer := attributer.Attributes()
+		if len(layer) == 0 {
+			return WalkContinue
+		}
+
+		if index == nil {
+			index = make(map[string]int, len(layer))
+		}
+
+		start := len(attrs)
+		for _, attr := range layer {
+			pos, found := index[attr.Key]
+			if !found {
+				index[attr.Key] = len(attrs)
+				attrs = append(attrs, attr)
+
+				continue
+			}
+
+			if pos >= start {
+				// overridden in the same layer
+				attrs[pos] = attr
+			}
+		}
+
+		return WalkContinue
+	})
+
+	return attrs
+}
+
+// With returns a clone of the wrapped error, which carries extra attributes.
+//
+// Arguments are key/value pairs, like with log/slog: keys are strings, or Attr values which are taken as a whole.
+// A missing value or a key which is not a string are reported under the "!BADKEY" key.
+func (e wrapped) With(keyvals ...interface{}) Wrappable {
+	reportDeprecated(e.err)
+
+	return &wrapped{
+		err:         e.err,
+		cause:       e.cause,
+		attrs:       appendAttrs(e.attrs, keyvals),
+		trackCaller: e.trackCaller,
+		pc:          e.pc,
+		causePC:     e.causePC,
+		origin:      e.origin,
+		causeOrigin: e.causeOrigin,
+		trace:       e.trace,
+	}
+}
+
+// Attributes returns the attributes attached to the wrapped error
+func (e wrapped) Attributes() []Attr {
+	return e.attrs
+}
+
+// With returns a wrapped error with extra attributes, with this chain as its stack of errors.
+func (c *chain) With(keyvals ...interface{}) Wrappable {
+	layer := c.layer()
+	layer.attrs = appendAttrs(nil, keyvals)
+
+	return &layer
+}
+
+// With returns a wrapped error with extra attributes, with the joined errors as topmost error.
+func (e *joined) With(keyvals ...interface{}) Wrappable {
+	return &wrapped{
+		err:   e,
+		attrs: appendAttrs(nil, keyvals),
+	}
+}
+
+// With returns a clone of the error with extra attributes, which retains the stack trace.
+func (s *stacked) With(keyvals ...interface{}) Wrappable {
+	return &stacked{
+		Wrappable: s.Wrappable.With(keyvals...),
+		stack:     s.stack,
+	}
+}
+
+// Attributes returns the attributes attached to the underlying error
+func (s *stacked) Attributes() []Attr {
+	if attributer, ok := s.Wrappable.(Attributer); ok {
+		return attributer.Attributes()
+	}
+
+	return nil
+}
+
+// With returns a clone of the original error of class T, with extra attributes.
+func (c Class[T]) With(keyvals ...interface{}) *T {
+	return c.clone(c.Wrappable.With(keyvals...))
+}
+
+// Attributes returns the attributes attached to the underlying error
+func (c Class[T]) Attributes() []Attr {
+	if attributer, ok := c.Wrappable.(Attributer); ok {
+		return attributer.Attributes()
+	}
+
+	return nil
+}
+
+// appendAttrs parses key/value pairs, and appends them to a copy of some attributes
+func appendAttrs(attrs []Attr, keyvals []interface{}) []Attr {
+	if len(keyvals) == 0 {
+		return attrs
+	}
+
+	result := make([]Attr, len(attrs), len(attrs)+len(keyvals)/2+1)
+	copy(result, attrs)
+
+	for len(keyvals) > 0 {
+		switch key := keyvals[0].(type) {
+		case Attr:
+			result = append(result, key)
+			keyvals = keyvals[1:]
+		case string:
+			if len(keyvals) == 1 {
+				result = append(result, Attr{Key: badKey, Value: key})
+				keyvals = nil
+
+				continue
+			}
+
+			result = append(result, Attr{Key: key, Value: keyvals[1]})
+			keyvals = keyvals[2:]
+		default:
+			result = append(result, Attr{Key: badKey, Value: key})
+			keyvals = keyvals[1:]
+		}
+	}
+
+	return result
+}
+
+// attrList renders attributes as a JSON object, retaining their order
+type attrList []Attr
+
+func (l attrList) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, attr := range l {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(attr.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(attr.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func (l *attrList) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		*l = nil
+
+		return nil
+	}
+
+	if token != json.Delim('{') {
+		return fmt.Errorf("invalid attributes: %v", token)
+	}
+
+	var attrs attrList
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("invalid attribute key: %v", token)
+		}
+
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		attrs = append(attrs, Attr{Key: key, Value: value})
+	}
+
+	*l = attrs
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWith(t *testing.T) {
+	t.Parallel()
+
+	sentinel := New(str)
+	e1 := sentinel.With("request_id", "abc", "user_id", 42)
+	e2 := e1.With("user_id", 43, Attr{Key: "resource", Value: "bucket"})
+
+	assert.Empty(t, Attributes(sentinel)) // the original error is not altered
+	assert.Equal(t, []Attr{{Key: "request_id", Value: "abc"}, {Key: "user_id", Value: 42}}, Attributes(e1))
+	assert.Equal(t, []Attr{{Key: "request_id", Value: "abc"}, {Key: "user_id", Value: 43}, {Key: "resource", Value: "bucket"}}, Attributes(e2))
+
+	assert.Equal(t, e1.Error(), sentinel.Error())
+	assert.True(t, Is(e2, sentinel))
+	assert.Equal(t, e2, e2.With())
+
+	// attributes are retained when wrapping
+	w := e1.Wrap(io.EOF).Errorf("message")
+	assert.Equal(t, Attributes(e1), Attributes(w))
+	assert.True(t, Is(w, io.EOF))
+
+	// bad keys
+	assert.Equal(t, []Attr{{Key: badKey, Value: 1}, {Key: "key", Value: 2}, {Key: badKey, Value: "missing"}},
+		New(str).With(1, "key", 2, "missing").(Attributer).Attributes())
+}
+
+func TestAttributesPrecedence(t *testing.T) {
+	t.Parallel()
+
+	inner := New("inner").With("resource", "inner", "inner_only", true)
+	middle := fmt.Errorf("middle: %w", inner)
+	outer := New("outer").With("resource", "outer").Wrap(middle).Wrap(Join(
+		New("joined").With("joined", 1, "resource", "joined"),
+	))
+
+	assert.Equal(t, []Attr{
+		{Key: "resource", Value: "outer"},
+		{Key: "inner_only", Value: true},
+		{Key: "joined", Value: 1},
+	}, Attributes(outer))
+
+	assert.Nil(t, Attributes(nil))
+	assert.Nil(t, Attributes(io.EOF))
+}
+
+func TestWithVariants(t *testing.T) {
+	t.Parallel()
+
+	chained := New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe)
+	tail := Unwrap(chained).(Wrappable).With("tail", true)
+	assert.Equal(t, "EOF: io: read/write on closed pipe", tail.Error())
+	assert.Equal(t, []Attr{{Key: "tail", Value: true}}, Attributes(tail))
+	assert.True(t, Is(tail, io.ErrClosedPipe))
+
+	joinedErr := Join(io.EOF, io.ErrClosedPipe).With("joined", true)
+	assert.Equal(t, "EOF\nio: read/write on closed pipe", joinedErr.Error())
+	assert.Equal(t, []Attr{{Key: "joined", Value: true}}, Attributes(joinedErr))
+
+	stackedErr := WithStack(io.EOF).With("stacked", true)
+	assert.Equal(t, []Attr{{Key: "stacked", Value: true}}, Attributes(stackedErr))
+	_, isTraceable := stackedErr.(Traceable)
+	assert.True(t, isTraceable)
+}
+
+func TestAttributesJSON(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).With("z", 1, "a", "first").Wrap(NewErr(io.EOF).With("nested", []int{1, 2})).Wrap(New("leaf").With("leaf", true))
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), `"attributes":{"z":1,"a":"first"}`)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+
+	attrs := Attributes(decoded)
+	require.Len(t, attrs, 4)
+	assert.Equal(t, []string{"z", "a", "nested", "leaf"}, []string{attrs[0].Key, attrs[1].Key, attrs[2].Key, attrs[3].Key})
+	assert.Equal(t, json.Number("1"), attrs[0].Value)
+	assert.Equal(t, true, attrs[3].Value)
+
+	rebuilt, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(buf), string(rebuilt))
+
+	var list attrList
+	require.Error(t, json.Unmarshal([]byte(`[]`), &list))
+	require.NoError(t, json.Unmarshal([]byte(`null`), &list))
+	assert.Nil(t, list)
+}
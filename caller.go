@@ -0,0 +1,150 @@
+package errors
+
+import (
+	"runtime"
+)
+
+// Option configures errors built with New or NewErr
+type Option func(*wrapped)
+
+// Located is an error which knows the location of the code which built it
+type Located interface {
+	error
+
+	// Caller returns the location of the code which built the error, if it has been captured
+	Caller() (Frame, bool)
+}
+
+var _ Located = &wrapped{}
+
+// WithCaller captures the location of the code calling New or NewErr.
+//
+// Errors derived from this error with Wrap or Errorf capture the location of their own caller as well,
+// so every layer in a chain of errors knows its origin: the topmost error retains the location of New,
+// and every stacked error the location of the Wrap or Errorf which stacked it. Only one program counter is captured for every layer:
+// this is much cheaper than capturing a full stack trace with WithStack.
+//
+// Locations are printed with the %+v verb, and exported to JSON.
+func WithCaller() Option {
+	return func(e *wrapped) {
+		e.trackCaller = true
+	}
+}
+
+// Caller returns the location captured by the outermost error in a chain which knows it (see WithCaller)
+func Caller(err error) (Frame, bool) {
+	var (
+		frame Frame
+		found bool
+	)
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		located, ok := node.(Located)
+		if !ok {
+			return WalkContinue
+		}
+
+		frame, found = located.Caller()
+		if found {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return frame, found
+}
+
+// Caller returns the location of the code which built this error, if it has been captured.
+//
+// This is the location of the latest Wrap or Errorf, or of New or NewErr when the error has not been derived.
+// The location of every layer is printed with the %+v verb.
+func (e wrapped) Caller() (Frame, bool) {
+	switch {
+	case e.causePC != 0:
+		return frameOf(e.causePC), true
+	case e.causeOrigin != nil:
+		return *e.causeOrigin, true
+	}
+
+	if tail, ok := e.cause.(*chain); ok {
+		if pc := tail.pc(len(tail.errs) - tail.start - 1); pc != 0 {
+			return frameOf(pc), true
+		}
+	}
+
+	return e.location()
+}
+
+// location of the code which built the topmost error, if it has been captured
+func (e wrapped) location() (Frame, bool) {
+	if e.origin != nil {
+		return *e.origin, true
+	}
+
+	if e.pc == 0 {
+		return Frame{}, false
+	}
+
+	return frameOf(e.pc), true
+}
+
+func frameOf(pc uintptr) Frame {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	return Frame{
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	}
+}
+
+func newWrapped(err error, opts []Option) *wrapped {
+	e := &wrapped{err: err}
+	for _, apply := range opts {
+		apply(e)
+	}
+
+	if !e.trackCaller && e.trace == nil {
+		return e
+	}
+
+	// skip New or NewErr
+	pc := callerPC(2)
+	if e.trackCaller {
+		e.pc = pc
+	}
+	if e.trace != nil {
+		e.trace = e.trace.append(pc)
+	}
+
+	return e
+}
+
+// callerPC captures the program counter of the frame depth levels above the function calling callerPC
+func callerPC(depth int) uintptr {
+	var pc [1]uintptr
+	if runtime.Callers(depth+2, pc[:]) == 0 {
+		return 0
+	}
+
+	return pc[0]
+}
+
+// Caller returns the location of the code which built the underlying error, if it has been captured
+func (s *stacked) Caller() (Frame, bool) {
+	if located, ok := s.Wrappable.(Located); ok {
+		return located.Caller()
+	}
+
+	return Frame{}, false
+}
+
+// Caller returns the location of the code which built the underlying error, if it has been captured
+func (c Class[T]) Caller() (Frame, bool) {
+	if located, ok := c.Wrappable.(Located); ok {
+		return located.Caller()
+	}
+
+	return Frame{}, false
+}
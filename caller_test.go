@@ -0,0 +1,222 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// line returns the current line in the test
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+
+	return l
+}
+
+func TestCaller(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should not capture callers by default", func(t *testing.T) {
+		_, ok := Caller(New("plain").Wrap(io.EOF))
+		assert.False(t, ok)
+	})
+
+	err, newLine := New("located", WithCaller()), line()
+	wrapped, wrapLine := err.Wrap(io.EOF), line()
+	errorf, errorfLine := err.Errorf("id %d", 42), line()
+	fromErr, fromErrLine := NewErr(io.ErrUnexpectedEOF, WithCaller()), line()
+
+	for _, tc := range []struct {
+		Title string
+		Err   error
+		Line  int
+	}{
+		{Title: "New", Err: err, Line: newLine},
+		{Title: "Wrap", Err: wrapped, Line: wrapLine},
+		{Title: "Errorf", Err: errorf, Line: errorfLine},
+		{Title: "NewErr", Err: fromErr, Line: fromErrLine},
+		{Title: "With", Err: wrapped.With("key", "value"), Line: wrapLine},
+		{Title: "nested", Err: New("outer").Wrap(errorf), Line: errorfLine},
+	} {
+		frame, ok := Caller(tc.Err)
+		require.True(t, ok, tc.Title)
+		assert.Equal(t, "caller_test.go", filepath.Base(frame.File), tc.Title)
+		assert.Equal(t, tc.Line, frame.Line, tc.Title)
+		assert.Contains(t, frame.Function, "TestCaller", tc.Title)
+	}
+
+	t.Run("should capture callers with classes", func(t *testing.T) {
+		type classError struct {
+			Class[classError]
+		}
+
+		errClass := NewErrOf[classError](New("class", WithCaller()))
+		classWrapped, classLine := errClass.Wrap(io.EOF), line()
+		classErrorf, classErrorfLine := errClass.Errorf("id %d", 42), line()
+
+		frame, ok := Caller(classWrapped)
+		require.True(t, ok)
+		assert.Equal(t, classLine, frame.Line)
+
+		frame, ok = Caller(classErrorf)
+		require.True(t, ok)
+		assert.Equal(t, classErrorfLine, frame.Line)
+
+		data, e := json.Marshal(NewErr(classErrorf))
+		require.NoError(t, e)
+
+		decoded, e := FromJSON(data)
+		require.NoError(t, e)
+		frame, ok = Caller(decoded)
+		require.True(t, ok)
+		assert.Equal(t, classErrorfLine, frame.Line)
+	})
+
+	t.Run("should capture callers with stacks", func(t *testing.T) {
+		stacked := WithStack(err)
+		stackedWrapped, stackedLine := stacked.Wrap(io.EOF), line()
+		stackedErrorf, stackedErrorfLine := stacked.Errorf("id %d", 42), line()
+
+		frame, ok := Caller(stackedWrapped)
+		require.True(t, ok)
+		assert.Equal(t, stackedLine, frame.Line)
+
+		frame, ok = Caller(stackedErrorf)
+		require.True(t, ok)
+		assert.Equal(t, stackedErrorfLine, frame.Line)
+	})
+
+	t.Run("should print every layer with its location", func(t *testing.T) {
+		outer, outerLine := New("outer", WithCaller()).Wrap(wrapped), line()
+
+		printed := fmt.Sprintf("%+v", outer)
+		lines := strings.Split(printed, "\n")
+		require.Len(t, lines, 7)
+		assert.Equal(t, "outer", lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], fmt.Sprintf("caller_test.go:%d", outerLine)), lines[1])
+		assert.Equal(t, "located", lines[2])
+		assert.True(t, strings.HasSuffix(lines[3], fmt.Sprintf("caller_test.go:%d", newLine)), lines[3])
+		assert.Equal(t, "EOF", lines[4])
+		assert.True(t, strings.HasSuffix(lines[5], fmt.Sprintf("caller_test.go:%d", wrapLine)), lines[5])
+		assert.True(t, strings.HasSuffix(lines[6], fmt.Sprintf("caller_test.go:%d", outerLine)), lines[6])
+
+		assert.Equal(t, "outer: located: EOF", fmt.Sprintf("%v", outer))
+	})
+
+	t.Run("should retain the location of every layer", func(t *testing.T) {
+		head, headLine := New("head", WithCaller()), line()
+		once, onceLine := head.Wrap(io.EOF), line()
+		twice, twiceLine := once.Wrap(io.ErrClosedPipe), line()
+		thrice, thriceLine := twice.Errorf("id %d", 42), line()
+		other, otherLine := twice.Wrap(io.ErrShortWrite), line() // shares the chain of twice
+
+		expected := []string{
+			"head", fmt.Sprintf("caller_test.go:%d", headLine),
+			"EOF", fmt.Sprintf("caller_test.go:%d", onceLine),
+			"io: read/write on closed pipe", fmt.Sprintf("caller_test.go:%d", twiceLine),
+			"id 42", fmt.Sprintf("caller_test.go:%d", thriceLine),
+		}
+
+		assertLayers := func(t *testing.T, err error, expected []string) {
+			t.Helper()
+
+			lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+			require.Len(t, lines, len(expected))
+			for i := 0; i < len(expected); i += 2 {
+				assert.Equal(t, expected[i], lines[i])
+				assert.True(t, strings.HasSuffix(lines[i+1], expected[i+1]), lines[i+1])
+			}
+		}
+
+		assertLayers(t, thrice, expected)
+		assertLayers(t, twice, expected[:6])
+		assertLayers(t, other, append(expected[:6:6], "short write", fmt.Sprintf("caller_test.go:%d", otherLine)))
+		assertLayers(t, thrice.With("key", "value"), expected)
+
+		frame, ok := Caller(thrice)
+		require.True(t, ok)
+		assert.Equal(t, thriceLine, frame.Line)
+
+		data, e := json.Marshal(thrice)
+		require.NoError(t, e)
+
+		decoded, e := FromJSON(data)
+		require.NoError(t, e)
+		assertLayers(t, decoded, expected)
+
+		frame, ok = Caller(decoded)
+		require.True(t, ok)
+		assert.Equal(t, thriceLine, frame.Line)
+
+		again, e := json.Marshal(decoded)
+		require.NoError(t, e)
+		assert.JSONEq(t, string(data), string(again))
+
+		var doc jsonError
+		require.NoError(t, json.Unmarshal(data, &doc))
+		for i, layer := 0, &doc; i < len(expected); i, layer = i+2, layer.Cause {
+			require.NotNil(t, layer)
+			assert.Equal(t, expected[i], layer.Message)
+			require.NotNil(t, layer.Caller)
+			assert.True(t, strings.HasSuffix(layer.Caller.String(), expected[i+1]), layer.Caller.String())
+		}
+	})
+
+	t.Run("should export locations to JSON", func(t *testing.T) {
+		outer := New("outer", WithCaller()).Wrap(wrapped)
+
+		data, e := json.Marshal(outer)
+		require.NoError(t, e)
+		assert.Contains(t, string(data), `"caller":{`)
+
+		decoded, e := FromJSON(data)
+		require.NoError(t, e)
+		assert.Equal(t, outer.Error(), decoded.Error())
+
+		expected, _ := Caller(outer)
+		frame, ok := Caller(decoded)
+		require.True(t, ok)
+		assert.Equal(t, expected, frame)
+		assert.Equal(t, fmt.Sprintf("%+v", outer), fmt.Sprintf("%+v", decoded))
+
+		again, e := json.Marshal(decoded)
+		require.NoError(t, e)
+		assert.JSONEq(t, string(data), string(again))
+	})
+}
+
+func BenchmarkWrapWithCaller(b *testing.B) {
+	b.Run("without caller", func(b *testing.B) {
+		err := New("plain")
+		b.ReportAllocs()
+
+		for range b.N {
+			_ = err.Wrap(io.EOF)
+		}
+	})
+
+	b.Run("with caller", func(b *testing.B) {
+		err := New("located", WithCaller())
+		b.ReportAllocs()
+
+		for range b.N {
+			_ = err.Wrap(io.EOF)
+		}
+	})
+
+	b.Run("with stack", func(b *testing.B) {
+		err := WithStack(New("stacked"))
+		b.ReportAllocs()
+
+		for range b.N {
+			_ = WithStack(err.Wrap(io.EOF))
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+var (
+	_ Wrappable      = &chain{}
+	_ MultiRootable  = &chain{}
+	_ fmt.Formatter  = &chain{}
+	_ json.Marshaler = &chain{}
+)
+
+// chain is an immutable sequence of errors, stacked from head to tail.
+//
+// Chains built from one another share the same backing array: errors are never modified
+// once stacked, and new errors are only appended beyond the end of the longest chain sharing this array.
+// Other chains copy their errors to a new array before appending.
+//
+// This makes stacking errors at the tail a constant time operation (amortized), and
+// unwrapping a chain does not allocate a new array.
+//
+// When locations are tracked (see WithCaller), the location of every stacked error is kept in a parallel
+// array, which is shared the same way.
+type chain struct {
+	errs  []error   // errs is never modified below len(errs)
+	pcs   []uintptr // pcs is either nil or parallel to errs
+	start int
+	owner *chainOwner
+}
+
+// chainOwner tracks the length of the longest chain sharing a backing array
+type chainOwner struct {
+	mx     sync.Mutex
+	length int
+}
+
+func newChain(errs ...error) *chain {
+	return &chain{
+		errs:  errs,
+		owner: &chainOwner{length: len(errs)},
+	}
+}
+
+// append an error at the tail of the chain, with the location which stacked it (or 0)
+func (c *chain) append(err error, pc uintptr) *chain {
+	c.owner.mx.Lock()
+	if len(c.errs) == c.owner.length {
+		// this is the longest chain on this array: errors and locations beyond its end are free
+		errs := append(c.errs, err)
+		c.owner.length++
+		c.owner.mx.Unlock()
+
+		return &chain{
+			errs:  errs,
+			pcs:   appendPC(c.pcs, len(c.errs), pc),
+			start: c.start,
+			owner: c.owner,
+		}
+	}
+	c.owner.mx.Unlock()
+
+	errs := make([]error, 0, 2*(len(c.errs)-c.start)+1)
+	errs = append(errs, c.errs[c.start:]...)
+
+	var pcs []uintptr
+	if c.pcs != nil {
+		pcs = make([]uintptr, 0, cap(errs))
+		pcs = append(pcs, c.pcs[c.start:]...)
+	}
+
+	clone := newChain(append(errs, err)...)
+	clone.pcs = appendPC(pcs, len(errs), pc)
+
+	return clone
+}
+
+// appendPC appends the location of the n-th error of a chain to the locations of the errors before it
+func appendPC(pcs []uintptr, n int, pc uintptr) []uintptr {
+	if pcs == nil {
+		if pc == 0 {
+			return nil
+		}
+
+		pcs = make([]uintptr, n, n+1)
+	}
+
+	return append(pcs, pc)
+}
+
+// pc yields the location of the i-th error in the chain, or 0
+func (c *chain) pc(i int) uintptr {
+	if c.pcs == nil {
+		return 0
+	}
+
+	return c.pcs[c.start+i]
+}
+
+// layer represents the chain as a wrapped error, with the locations of its errors
+func (c *chain) layer() wrapped {
+	layer := wrapped{
+		err:   c.Err(),
+		cause: c.Unwrap(),
+		pc:    c.pc(0),
+	}
+	if _, isChain := layer.cause.(*chain); !isChain {
+		layer.causePC = c.pc(1)
+	}
+
+	return layer
+}
+
+// MarshalJSON produces a JSON document describing the stacked errors, like the wrapped error it is part of.
+func (c *chain) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.layer().encode())
+}
+
+// Error implements the error interface, with plain formatting.
+// Stacked errors are printed, separated by a ":".
+func (c *chain) Error() string {
+	var b strings.Builder
+
+	for i, err := range c.errs[c.start:] {
+		if i > 0 {
+			b.WriteString(": ")
+		}
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+// Wrap another error at the tail of the chain.
+func (c *chain) Wrap(err error) Wrappable {
+	if err == nil {
+		return c
+	}
+
+	return c.append(err, 0)
+}
+
+// Errorf wraps a nested error built from the extra message.
+func (c *chain) Errorf(format string, args ...interface{}) Wrappable {
+	return c.Wrap(fmt.Errorf(format, args...))
+}
+
+// Unwrap implements errors.Unwrap: it returns the chain without its topmost error
+func (c *chain) Unwrap() error {
+	if len(c.errs)-c.start == 2 {
+		return c.errs[c.start+1]
+	}
+
+	return &chain{
+		errs:  c.errs,
+		pcs:   c.pcs,
+		start: c.start + 1,
+		owner: c.owner,
+	}
+}
+
+// Err returns the topmost error in the chain
+func (c *chain) Err() error {
+	return c.errs[c.start]
+}
+
+// Is implements errors.Is
+func (c *chain) Is(err error) bool {
+	if c == err {
+		return true
+	}
+
+	if err == nil {
+		return false
+	}
+
+	if matcher, ok := err.(*Matcher); ok {
+		return matcher.Match(c)
+	}
+
+	reportDeprecated(err)
+
+	head := c.Err()
+	if errors.Is(head, err) || inherits(head, err) {
+		return true
+	}
+
+	// special case for another wrapped error
+	errable, ok := err.(*wrapped)
+
+	return ok && errors.Is(head, errable.err)
+}
+
+// As implements errors.As
+func (c *chain) As(target interface{}) bool {
+	return as(c, target) || errors.As(c.Err(), target)
+}
+
+// Root returns the root cause of the chain
+func (c *chain) Root() error {
+	return rootOf(c)
+}
+
+// RootCauses returns the root causes of the chain
+func (c *chain) RootCauses() []error {
+	return appendRootCauses(nil, c.Unwrap())
+}
+
+// Format implements fmt.Formatter, like for wrapped errors.
+func (c *chain) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for i, err := range c.errs[c.start:] {
+				if i > 0 {
+					_, _ = io.WriteString(s, "\n")
+				}
+				fmt.Fprintf(s, "%+v", err)
+				if pc := c.pc(i); pc != 0 {
+					fmt.Fprintf(s, "\n\t%s", frameOf(pc))
+				}
+			}
+
+			return
+		case s.Flag('#'):
+			_, _ = io.WriteString(s, "&errors.chain{errs:[]error{")
+			for i, err := range c.errs[c.start:] {
+				if i > 0 {
+					_, _ = io.WriteString(s, ", ")
+				}
+				fmt.Fprintf(s, "%#v", err)
+			}
+			_, _ = io.WriteString(s, "}}")
+
+			return
+		}
+
+		_, _ = io.WriteString(s, c.Error())
+	case 's':
+		_, _ = io.WriteString(s, c.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", c.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%s)", verb, c.Error())
+	}
+}
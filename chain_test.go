@@ -0,0 +1,179 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainImmutable(t *testing.T) {
+	t.Parallel()
+
+	base := New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe)
+
+	// branching from the same error does not alter other branches
+	b1 := base.Wrap(io.ErrShortBuffer)
+	b2 := base.Wrap(io.ErrShortWrite)
+	b11 := b1.Wrap(io.ErrNoProgress)
+	b21 := b2.Wrap(io.ErrUnexpectedEOF)
+
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe", base.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short buffer", b1.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short write", b2.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short buffer: multiple Read calls return no data or error", b11.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short write: unexpected EOF", b21.Error())
+
+	assert.False(t, Is(base, io.ErrShortBuffer))
+	assert.False(t, Is(b1, io.ErrShortWrite))
+	assert.True(t, Is(b21, io.ErrShortWrite))
+	assert.ErrorIs(t, Root(b11), io.ErrNoProgress)
+	assert.ErrorIs(t, Root(b21), io.ErrUnexpectedEOF)
+
+	// wrapping an unwrapped chain
+	tail := Unwrap(b1).(Wrappable)
+	assert.Equal(t, "EOF: io: read/write on closed pipe: short buffer", tail.Error())
+	t1 := tail.Wrap(io.ErrUnexpectedEOF)
+	assert.Equal(t, "EOF: io: read/write on closed pipe: short buffer: unexpected EOF", t1.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short buffer", b1.Error())
+	assert.Equal(t, str+": EOF: io: read/write on closed pipe: short buffer: multiple Read calls return no data or error", b1.Wrap(io.ErrNoProgress).Error())
+}
+
+func TestChainUnwrap(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe).Wrap(io.ErrShortBuffer)
+
+	u1 := Unwrap(e)
+	u2 := Unwrap(u1)
+	u3 := Unwrap(u2)
+
+	assert.Equal(t, "EOF: io: read/write on closed pipe: short buffer", u1.Error())
+	assert.Equal(t, "io: read/write on closed pipe: short buffer", u2.Error())
+	assert.Equal(t, io.ErrShortBuffer, u3)
+	assert.Equal(t, io.EOF, u1.(Wrappable).Err())
+
+	assert.True(t, Is(u1, io.EOF))
+	assert.False(t, Is(u2, io.EOF))
+	assert.True(t, Is(u2, u2))
+	assert.False(t, u2.(interface{ Is(error) bool }).Is(nil))
+
+	var target *chain
+	require.True(t, As(u1, &target))
+	assert.Equal(t, u1, target)
+
+	assert.Equal(t, []error{io.ErrShortBuffer}, RootCauses(u1))
+	assert.Equal(t, io.ErrShortBuffer, u1.(Rootable).Root())
+
+	// foreign causes are retained as is
+	w := New(str).Wrap(fmt.Errorf("message: %w", io.EOF)).Wrap(io.ErrClosedPipe)
+	assert.Equal(t, str+": message: EOF: io: read/write on closed pipe", w.Error())
+	assert.True(t, Is(w, io.EOF))
+}
+
+func TestChainConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const n = 64
+	base := New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe)
+	results := make([]Wrappable, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			results[i] = base.Wrap(myError(strconv.Itoa(i)))
+		}(i)
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		assert.Equal(t, base.Error()+": "+strconv.Itoa(i), result.Error())
+	}
+}
+
+func TestChainFormat(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe)
+	tail := Unwrap(e)
+
+	assert.Equal(t, "test error\nEOF\nio: read/write on closed pipe", fmt.Sprintf("%+v", e))
+	assert.Equal(t, tail.Error(), fmt.Sprintf("%v", tail))
+	assert.Equal(t, fmt.Sprintf("%q", tail.Error()), fmt.Sprintf("%q", tail))
+	assert.Equal(t,
+		`&errors.chain{errs:[]error{&errors.errorString{s:"EOF"}, &errors.errorString{s:"io: read/write on closed pipe"}}}`,
+		fmt.Sprintf("%#v", tail),
+	)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+
+	buf, err = json.Marshal(tail)
+	require.NoError(t, err)
+	decoded, err = FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, tail.Error(), decoded.Error())
+	assert.Equal(t, io.ErrClosedPipe.Error(), Root(decoded).Error())
+}
+
+// legacyWrap is the former implementation of Wrap, which rebuilds the stack of causes.
+// It is retained to benchmark the current implementation.
+func legacyWrap(e *wrapped, err error) *wrapped {
+	if e.cause == nil {
+		return &wrapped{err: e.err, cause: err}
+	}
+
+	if cause, ok := e.cause.(*wrapped); ok {
+		return &wrapped{err: e.err, cause: legacyWrap(cause, err)}
+	}
+
+	return &wrapped{err: e.err, cause: &wrapped{err: e.cause, cause: err}}
+}
+
+func BenchmarkWrap(b *testing.B) {
+	for _, depth := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("chain-%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				e := New(str)
+				for j := 0; j < depth; j++ {
+					e = e.Wrap(io.EOF)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("legacy-%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				e := &wrapped{err: io.EOF}
+				for j := 0; j < depth; j++ {
+					e = legacyWrap(e, io.EOF)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkErrorf(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		e := New(str)
+		for j := 0; j < 100; j++ {
+			e = e.Errorf("message %d", j)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Class is a base type for custom error classes, which retain their type when wrapping other errors.
+//
+// A custom error class embeds Class, parameterized by the class itself:
+//
+//	type MyErrorType struct {
+//		errors.Class[MyErrorType]
+//
+//		Code int `json:"code"`
+//	}
+//
+//	var ErrMyErr1 = errors.NewOf[MyErrorType]("err1")
+//
+// Values of the class are built with NewOf() or NewErrOf(). Wrap() and Errorf() then return a *MyErrorType,
+// which retains the custom fields of the original value.
+type Class[T any] struct {
+	Wrappable `json:"-"`
+
+	self *T
+}
+
+// classPtr constrains a type parameter to be a pointer to a custom error class
+type classPtr[T any] interface {
+	*T
+
+	setWrappable(Wrappable, *T)
+}
+
+// NewOf builds a new error of class T from a string
+func NewOf[T any, PT classPtr[T]](msg string) *T {
+	return NewErrOf[T, PT](New(msg))
+}
+
+// NewErrOf builds a new error of class T from another error
+func NewErrOf[T any, PT classPtr[T]](err error) *T {
+	wrapper, ok := err.(Wrappable)
+	if !ok {
+		wrapper = NewErr(err)
+	}
+
+	e := new(T)
+	PT(e).setWrappable(wrapper, e)
+
+	return e
+}
+
+// Wrap another error. Returns a shallow clone of the original error of class T.
+//
+// Values of class T which have not been built with NewOf() or NewErrOf() don't retain their custom fields.
+func (c Class[T]) Wrap(err error) *T {
+	return c.wrap(err, 1)
+}
+
+// Errorf wraps a nested error built from the extra message, like fmt.Errorf() does.
+func (c Class[T]) Errorf(format string, args ...interface{}) *T {
+	return c.wrap(fmt.Errorf(format, args...), 1)
+}
+
+// wrap another error, capturing the location of the caller depth frames above when it is tracked (see WithCaller)
+func (c Class[T]) wrap(err error, depth int) *T {
+	if err == nil && c.self != nil {
+		return c.self
+	}
+
+	if inner, ok := c.Wrappable.(*wrapped); ok {
+		return c.clone(inner.wrap(err, depth+1))
+	}
+
+	return c.clone(c.Wrappable.Wrap(err))
+}
+
+// Is implements errors.Is.
+//
+// Errors of class T match other errors of class T which have been derived from the same original error.
+func (c Class[T]) Is(err error) bool {
+	if c.Wrappable == nil {
+		return false
+	}
+
+	if matcher, ok := err.(*Matcher); ok {
+		if self, isError := any(c.self).(error); isError && c.self != nil {
+			return matcher.Match(self)
+		}
+
+		return matcher.Match(c.Wrappable)
+	}
+
+	if other, ok := err.(interface{ classWrappable() Wrappable }); ok {
+		if inner := other.classWrappable(); inner != nil && errors.Is(c.Wrappable, inner) {
+			return true
+		}
+	}
+
+	return errors.Is(c.Wrappable, err)
+}
+
+// Format implements fmt.Formatter, like the underlying Wrappable.
+func (c Class[T]) Format(s fmt.State, verb rune) {
+	if formatter, ok := c.Wrappable.(fmt.Formatter); ok {
+		formatter.Format(s, verb)
+
+		return
+	}
+
+	_, _ = io.WriteString(s, c.Error())
+}
+
+// MarshalJSON produces a JSON document describing the stack of errors, with the custom fields of class T.
+// See FromJSON.
+func (c Class[T]) MarshalJSON() ([]byte, error) {
+	if self, isError := any(c.self).(error); isError && c.self != nil {
+		return json.Marshal(encodeError(self))
+	}
+
+	return json.Marshal(encodeError(c.Wrappable))
+}
+
+func (c *Class[T]) setWrappable(wrapper Wrappable, self *T) {
+	c.Wrappable = wrapper
+	c.self = self
+}
+
+func (c Class[T]) classWrappable() Wrappable {
+	return c.Wrappable
+}
+
+func (c Class[T]) clone(wrapper Wrappable) *T {
+	e := new(T)
+	if c.self != nil {
+		*e = *c.self
+	}
+
+	setter, ok := interface{}(e).(interface{ setWrappable(Wrappable, *T) })
+	if !ok {
+		panic(fmt.Sprintf("wrappable-errors: %T must embed Class", e))
+	}
+	setter.setWrappable(wrapper, e)
+
+	return e
+}
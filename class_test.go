@@ -0,0 +1,131 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	errors "github.com/fredbi/wrappable-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ClassError represents a class of errors, with no boilerplate to retain its type when wrapping.
+type ClassError struct {
+	errors.Class[ClassError]
+
+	Code int `json:"code"`
+}
+
+func newClassError(msg string, code int) *ClassError {
+	e := errors.NewOf[ClassError](msg)
+	e.Code = code
+
+	return e
+}
+
+var (
+	// ErrClass1 represents some package level error. Its value won't change
+	ErrClass1 = newClassError("class1", 1)
+
+	// ErrClass2 represents some other package level error. Its value won't change
+	ErrClass2 = newClassError("class2", 2)
+)
+
+func init() {
+	errors.RegisterClass("test.ClassError", func(inner errors.Wrappable) error {
+		return errors.NewErrOf[ClassError](inner)
+	})
+}
+
+func TestClass(t *testing.T) {
+	e := &ClassError{} // template value
+
+	err1 := ErrClass1.Wrap(io.EOF)                // class1 <- EOF
+	err2 := err1.Errorf("message: %w", ErrClass2) // class1 <- EOF <- message: class2
+
+	assert.IsType(t, e, err1)
+	assert.IsType(t, e, err2)
+	assert.Equal(t, "class1: EOF: message: class2", err2.Error())
+	assert.Equal(t, 1, err2.Code) // custom fields are retained
+	assert.Equal(t, 1, ErrClass1.Code)
+	assert.Equal(t, "class1", ErrClass1.Error()) // sentinels are not altered
+	assert.Equal(t, ErrClass1, ErrClass1.Wrap(nil))
+
+	assert.True(t, errors.Is(err2, io.EOF))
+	assert.True(t, errors.Is(err2, ErrClass1))
+	assert.True(t, errors.Is(err2, ErrClass2))
+	assert.True(t, errors.Is(err1, ErrClass1))
+	assert.False(t, errors.Is(err1, ErrClass2))
+	assert.False(t, errors.Is(ErrClass2, ErrClass1))
+	assert.Equal(t, "class2", errors.Root(err2).Error())
+
+	var target *ClassError
+	require.True(t, errors.As(fmt.Errorf("outer: %w", err2), &target))
+	assert.Equal(t, err2, target)
+
+	assert.Equal(t, "class1\nEOF\nmessage: class2", fmt.Sprintf("%+v", err2))
+	assert.Equal(t, err2.Error(), fmt.Sprintf("%v", err2))
+}
+
+func TestClassWith(t *testing.T) {
+	e := ErrClass1.With("resource", "user").Wrap(io.EOF)
+
+	assert.IsType(t, &ClassError{}, e)
+	assert.Equal(t, 1, e.Code)
+	assert.Equal(t, []errors.Attr{{Key: "resource", Value: "user"}}, errors.Attributes(e))
+	assert.Empty(t, errors.Attributes(ErrClass1))
+	assert.True(t, errors.Is(e, ErrClass1))
+
+	buf, err := json.Marshal(errors.NewErr(e))
+	require.NoError(t, err)
+
+	decoded, err := errors.FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "user", errors.Attributes(decoded)[0].Value)
+}
+
+func TestClassFromErr(t *testing.T) {
+	e1 := errors.NewErrOf[ClassError](io.EOF)
+	assert.Equal(t, "EOF", e1.Error())
+	assert.True(t, errors.Is(e1, io.EOF))
+	assert.Zero(t, e1.Code)
+
+	e2 := errors.NewErrOf[ClassError](errors.New("wrappable").Wrap(io.EOF))
+	assert.Equal(t, "wrappable: EOF", e2.Wrap(nil).Error())
+}
+
+func TestClassJSON(t *testing.T) {
+	err := errors.New("outer").Wrap(ErrClass1.Wrap(io.EOF))
+
+	buf, e := json.Marshal(err)
+	require.NoError(t, e)
+
+	decoded, e := errors.FromJSON(buf)
+	require.NoError(t, e)
+	assert.Equal(t, err.Error(), decoded.Error())
+
+	var target *ClassError
+	require.True(t, errors.As(decoded, &target))
+	assert.Equal(t, 1, target.Code)
+	assert.Equal(t, "class1: EOF", target.Error())
+	assert.Equal(t, "class1", target.Err().Error())
+}
+
+func TestClassMarshalJSON(t *testing.T) {
+	e := ErrClass1.Wrap(io.EOF)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+	assert.Contains(t, string(buf), `"code":1`)
+
+	decoded, err := errors.FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+
+	var target *ClassError
+	require.True(t, errors.As(decoded, &target))
+	assert.Equal(t, 1, target.Code)
+	assert.Equal(t, "class1: EOF", target.Error())
+}
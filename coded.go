@@ -0,0 +1,149 @@
+package errors
+
+// Coded is an error which carries an integer code
+type Coded interface {
+	error
+
+	Code() int
+}
+
+// Kinded is an error which carries a kind, as a string
+type Kinded interface {
+	error
+
+	Kind() string
+}
+
+// Order tells which error wins when several errors in a chain carry a code or a kind
+type Order int
+
+const (
+	// Outermost picks the first error found when walking the chain from the head, i.e. the most recently wrapped one
+	Outermost Order = iota
+
+	// Innermost picks the last error found when walking the chain from the head, i.e. the closest to the root cause
+	Innermost
+)
+
+var (
+	_ Coded  = &codedError{}
+	_ Kinded = &kindedError{}
+)
+
+func init() {
+	RegisterClass("wrappable-errors.coded", func(inner Wrappable) error {
+		return &codedError{Message: inner.Error()}
+	})
+
+	RegisterClass("wrappable-errors.kinded", func(inner Wrappable) error {
+		return &kindedError{Message: inner.Error()}
+	})
+}
+
+// NewCoded builds a wrappable error from a string, with an integer code.
+//
+// The code is retained when wrapping other errors.
+func NewCoded(code int, msg string) Wrappable {
+	return &wrapped{err: &codedError{Value: code, Message: msg}}
+}
+
+// NewKinded builds a wrappable error from a string, with a kind.
+//
+// The kind is retained when wrapping other errors.
+func NewKinded(kind string, msg string) Wrappable {
+	return &wrapped{err: &kindedError{Value: kind, Message: msg}}
+}
+
+// Code returns the outermost code found in a chain of errors.
+//
+// This is a shorthand for FindCode(err, Outermost).
+func Code(err error) (int, bool) {
+	return FindCode(err, Outermost)
+}
+
+// Kind returns the outermost kind found in a chain of errors.
+//
+// This is a shorthand for FindKind(err, Outermost).
+func Kind(err error) (string, bool) {
+	return FindKind(err, Outermost)
+}
+
+// FindCode returns the code carried by an error in a chain of errors, walking the chain like Walk.
+//
+// Errors are Coded either because they have been built with NewCoded(), or because they implement the Coded interface.
+func FindCode(err error, order Order) (int, bool) {
+	coded, ok := find[Coded](err, order)
+	if !ok {
+		return 0, false
+	}
+
+	return coded.Code(), true
+}
+
+// FindKind returns the kind carried by an error in a chain of errors, walking the chain like Walk.
+//
+// Errors are Kinded either because they have been built with NewKinded(), or because they implement the Kinded interface.
+func FindKind(err error, order Order) (string, bool) {
+	kinded, ok := find[Kinded](err, order)
+	if !ok {
+		return "", false
+	}
+
+	return kinded.Kind(), true
+}
+
+// find the outermost or innermost error of type T in a chain of errors
+func find[T any](err error, order Order) (T, bool) {
+	var (
+		found T
+		ok    bool
+	)
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		match, isMatch := node.(T)
+		if !isMatch {
+			return WalkContinue
+		}
+
+		found, ok = match, true
+		if order == Outermost {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return found, ok
+}
+
+// codedError is an error with a code
+type codedError struct {
+	Value   int    `json:"code"`
+	Message string `json:"-"`
+}
+
+func (e *codedError) Error() string {
+	return e.Message
+}
+
+func (e *codedError) ownedByPackage() {}
+
+func (e *codedError) Code() int {
+	return e.Value
+}
+
+// kindedError is an error with a kind
+type kindedError struct {
+	Value   string `json:"kind"`
+	Message string `json:"-"`
+}
+
+func (e *kindedError) Error() string {
+	return e.Message
+}
+
+func (e *kindedError) ownedByPackage() {}
+
+func (e *kindedError) Kind() string {
+	return e.Value
+}
@@ -0,0 +1,98 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type customCoded struct {
+	code int
+}
+
+func (e customCoded) Error() string {
+	return fmt.Sprintf("custom code %d", e.code)
+}
+
+func (e customCoded) Code() int {
+	return e.code
+}
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := NewCoded(404, "not found")
+	errInvalid := NewCoded(400, "invalid")
+
+	e := errNotFound.Wrap(io.EOF)
+	assert.Equal(t, "not found: EOF", e.Error())
+	assert.True(t, Is(e, errNotFound))
+
+	code, ok := Code(e)
+	require.True(t, ok)
+	assert.Equal(t, 404, code)
+
+	nested := New("outer").Wrap(fmt.Errorf("handler: %w", e)).Wrap(errInvalid).Wrap(customCoded{code: 500})
+
+	code, ok = Code(nested)
+	require.True(t, ok)
+	assert.Equal(t, 404, code)
+
+	code, ok = FindCode(nested, Innermost)
+	require.True(t, ok)
+	assert.Equal(t, 500, code)
+
+	_, ok = Code(New("plain").Wrap(io.EOF))
+	assert.False(t, ok)
+
+	_, ok = FindCode(nil, Innermost)
+	assert.False(t, ok)
+}
+
+func TestKind(t *testing.T) {
+	t.Parallel()
+
+	errClient := NewKinded("client", "client error")
+	errServer := NewKinded("server", "server error")
+
+	e := New("outer").Wrap(errClient.Wrap(io.EOF)).Wrap(Join(io.ErrClosedPipe, errServer))
+
+	kind, ok := Kind(e)
+	require.True(t, ok)
+	assert.Equal(t, "client", kind)
+
+	kind, ok = FindKind(e, Innermost)
+	require.True(t, ok)
+	assert.Equal(t, "server", kind)
+
+	_, ok = Code(e)
+	assert.False(t, ok)
+
+	_, ok = Kind(io.EOF)
+	assert.False(t, ok)
+}
+
+func TestCodeJSON(t *testing.T) {
+	t.Parallel()
+
+	e := New("outer").Wrap(NewCoded(404, "not found").Wrap(NewKinded("client", "client error")))
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+
+	code, ok := Code(decoded)
+	require.True(t, ok)
+	assert.Equal(t, 404, code)
+
+	kind, ok := Kind(decoded)
+	require.True(t, ok)
+	assert.Equal(t, "client", kind)
+}
@@ -0,0 +1,177 @@
+// Package codes defines canonical error codes, mirroring the status codes used by gRPC.
+//
+// The values and names of codes are the same as in google.golang.org/grpc/codes, so they may be
+// converted with a simple type conversion, without this module depending on gRPC.
+package codes
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Code is a canonical error code
+type Code uint32
+
+const (
+	// OK is returned on success
+	OK Code = 0
+
+	// Canceled indicates the operation was canceled, typically by the caller
+	Canceled Code = 1
+
+	// Unknown error
+	Unknown Code = 2
+
+	// InvalidArgument indicates the client specified an invalid argument
+	InvalidArgument Code = 3
+
+	// DeadlineExceeded means the operation expired before completion
+	DeadlineExceeded Code = 4
+
+	// NotFound means some requested entity was not found
+	NotFound Code = 5
+
+	// AlreadyExists means an attempt to create an entity failed because one already exists
+	AlreadyExists Code = 6
+
+	// PermissionDenied indicates the caller does not have permission to execute the operation
+	PermissionDenied Code = 7
+
+	// ResourceExhausted indicates some resource has been exhausted, e.g. a quota
+	ResourceExhausted Code = 8
+
+	// FailedPrecondition indicates the operation was rejected because the system is not in a state required
+	// for the operation's execution
+	FailedPrecondition Code = 9
+
+	// Aborted indicates the operation was aborted, typically due to a concurrency issue
+	Aborted Code = 10
+
+	// OutOfRange means the operation was attempted past the valid range
+	OutOfRange Code = 11
+
+	// Unimplemented indicates the operation is not implemented or not supported
+	Unimplemented Code = 12
+
+	// Internal errors: some invariant expected by the underlying system has been broken
+	Internal Code = 13
+
+	// Unavailable indicates the service is currently unavailable. This is most likely a transient condition
+	Unavailable Code = 14
+
+	// DataLoss indicates unrecoverable data loss or corruption
+	DataLoss Code = 15
+
+	// Unauthenticated indicates the request does not have valid authentication credentials
+	Unauthenticated Code = 16
+)
+
+var names = [...]string{
+	OK:                 "OK",
+	Canceled:           "Canceled",
+	Unknown:            "Unknown",
+	InvalidArgument:    "InvalidArgument",
+	DeadlineExceeded:   "DeadlineExceeded",
+	NotFound:           "NotFound",
+	AlreadyExists:      "AlreadyExists",
+	PermissionDenied:   "PermissionDenied",
+	ResourceExhausted:  "ResourceExhausted",
+	FailedPrecondition: "FailedPrecondition",
+	Aborted:            "Aborted",
+	OutOfRange:         "OutOfRange",
+	Unimplemented:      "Unimplemented",
+	Internal:           "Internal",
+	Unavailable:        "Unavailable",
+	DataLoss:           "DataLoss",
+	Unauthenticated:    "Unauthenticated",
+}
+
+var httpStatuses = [...]int{
+	OK:                 http.StatusOK,
+	Canceled:           499, // client closed request
+	Unknown:            http.StatusInternalServerError,
+	InvalidArgument:    http.StatusBadRequest,
+	DeadlineExceeded:   http.StatusGatewayTimeout,
+	NotFound:           http.StatusNotFound,
+	AlreadyExists:      http.StatusConflict,
+	PermissionDenied:   http.StatusForbidden,
+	ResourceExhausted:  http.StatusTooManyRequests,
+	FailedPrecondition: http.StatusBadRequest,
+	Aborted:            http.StatusConflict,
+	OutOfRange:         http.StatusBadRequest,
+	Unimplemented:      http.StatusNotImplemented,
+	Internal:           http.StatusInternalServerError,
+	Unavailable:        http.StatusServiceUnavailable,
+	DataLoss:           http.StatusInternalServerError,
+	Unauthenticated:    http.StatusUnauthorized,
+}
+
+// String representation of a code, e.g. "NotFound"
+func (c Code) String() string {
+	if int(c) < len(names) {
+		return names[c]
+	}
+
+	return "Code(" + strconv.FormatUint(uint64(c), 10) + ")"
+}
+
+// HTTPStatus returns the HTTP status a code maps to, following the conventions of gRPC gateways.
+//
+// Unknown codes map to 500.
+func (c Code) HTTPStatus() int {
+	if int(c) < len(httpStatuses) {
+		return httpStatuses[c]
+	}
+
+	return http.StatusInternalServerError
+}
+
+// FromHTTPStatus returns the code an HTTP status maps to.
+func FromHTTPStatus(status int) Code {
+	switch status {
+	case http.StatusOK:
+		return OK
+	case 499:
+		return Canceled
+	case http.StatusBadRequest:
+		return InvalidArgument
+	case http.StatusUnauthorized:
+		return Unauthenticated
+	case http.StatusForbidden:
+		return PermissionDenied
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusConflict:
+		return AlreadyExists
+	case http.StatusTooManyRequests:
+		return ResourceExhausted
+	case http.StatusNotImplemented:
+		return Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return Unavailable
+	case http.StatusGatewayTimeout:
+		return DeadlineExceeded
+	}
+
+	switch {
+	case status >= 200 && status < 300:
+		return OK
+	case status >= 400 && status < 500:
+		return FailedPrecondition
+	case status >= 500:
+		return Internal
+	}
+
+	return Unknown
+}
+
+// Parse the name of a code, e.g. "NotFound"
+func Parse(name string) (Code, bool) {
+	for c, n := range names {
+		if n == name {
+			return Code(c), true
+		}
+	}
+
+	return Unknown, false
+}
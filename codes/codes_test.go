@@ -0,0 +1,70 @@
+package codes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	// values must remain aligned with google.golang.org/grpc/codes
+	for _, tc := range []struct {
+		Code   Code
+		Value  uint32
+		Name   string
+		Status int
+	}{
+		{Code: OK, Value: 0, Name: "OK", Status: http.StatusOK},
+		{Code: Canceled, Value: 1, Name: "Canceled", Status: 499},
+		{Code: Unknown, Value: 2, Name: "Unknown", Status: http.StatusInternalServerError},
+		{Code: InvalidArgument, Value: 3, Name: "InvalidArgument", Status: http.StatusBadRequest},
+		{Code: DeadlineExceeded, Value: 4, Name: "DeadlineExceeded", Status: http.StatusGatewayTimeout},
+		{Code: NotFound, Value: 5, Name: "NotFound", Status: http.StatusNotFound},
+		{Code: AlreadyExists, Value: 6, Name: "AlreadyExists", Status: http.StatusConflict},
+		{Code: PermissionDenied, Value: 7, Name: "PermissionDenied", Status: http.StatusForbidden},
+		{Code: ResourceExhausted, Value: 8, Name: "ResourceExhausted", Status: http.StatusTooManyRequests},
+		{Code: FailedPrecondition, Value: 9, Name: "FailedPrecondition", Status: http.StatusBadRequest},
+		{Code: Aborted, Value: 10, Name: "Aborted", Status: http.StatusConflict},
+		{Code: OutOfRange, Value: 11, Name: "OutOfRange", Status: http.StatusBadRequest},
+		{Code: Unimplemented, Value: 12, Name: "Unimplemented", Status: http.StatusNotImplemented},
+		{Code: Internal, Value: 13, Name: "Internal", Status: http.StatusInternalServerError},
+		{Code: Unavailable, Value: 14, Name: "Unavailable", Status: http.StatusServiceUnavailable},
+		{Code: DataLoss, Value: 15, Name: "DataLoss", Status: http.StatusInternalServerError},
+		{Code: Unauthenticated, Value: 16, Name: "Unauthenticated", Status: http.StatusUnauthorized},
+	} {
+		assert.Equal(t, tc.Value, uint32(tc.Code), tc.Name)
+		assert.Equal(t, tc.Name, tc.Code.String(), tc.Name)
+		assert.Equal(t, tc.Status, tc.Code.HTTPStatus(), tc.Name)
+
+		parsed, ok := Parse(tc.Name)
+		assert.True(t, ok, tc.Name)
+		assert.Equal(t, tc.Code, parsed, tc.Name)
+	}
+
+	t.Run("with out of range code", func(t *testing.T) {
+		assert.Equal(t, "Code(42)", Code(42).String())
+		assert.Equal(t, http.StatusInternalServerError, Code(42).HTTPStatus())
+
+		_, ok := Parse("Whatever")
+		assert.False(t, ok)
+	})
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, code := range []Code{
+		OK, Canceled, InvalidArgument, DeadlineExceeded, NotFound, PermissionDenied,
+		ResourceExhausted, Unimplemented, Unavailable, Unauthenticated,
+	} {
+		assert.Equal(t, code, FromHTTPStatus(code.HTTPStatus()), code.String())
+	}
+
+	assert.Equal(t, OK, FromHTTPStatus(http.StatusNoContent))
+	assert.Equal(t, FailedPrecondition, FromHTTPStatus(http.StatusTeapot))
+	assert.Equal(t, Internal, FromHTTPStatus(http.StatusInternalServerError))
+	assert.Equal(t, Unknown, FromHTTPStatus(http.StatusFound))
+}
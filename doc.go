@@ -9,7 +9,9 @@
 // As its simplest, this package may be used to derive error values or types and proceed with type or value assertion on
 // sentinel errors using Wrap() and Is() or As().
 //
-// Runtime stack trace capture is provided as an optional addon (using WithStackTrace()).
+// Runtime stack trace capture is provided as an optional addon (using WithStack()).
+// A cheaper alternative captures only the location of the caller for every layer of errors (using the WithCaller() option),
+// or a return trace of the locations an error went through (using Trace()).
 //
 // To capture the root cause of an error stack (i.e. the deepest error in the stack), one can use the Root() method.
 package errors
@@ -21,6 +21,9 @@ type Wrappable interface {
 
 	// Err returns the topmost error in the stack (head)
 	Err() error
+
+	// With returns a clone of the current error, which carries extra attributes as key/value pairs
+	With(...interface{}) Wrappable
 }
 
 // Rootable is a Wrappable that knows how to yield its head and tail
@@ -39,9 +42,16 @@ type Rootable interface {
 // Is behaves like errors.Is from the standard library.
 //
 // This method is only provided for this package to nicely supersede standard lib errors:
-// it just calls Is() from the standard library.
+// it calls Is() from the standard library, then resolves the hierarchy of errors declared with DeclareParent,
+// Alias or Deprecate. A *Matcher target is matched against every error in the chain.
 func Is(err, target error) bool {
-	return errors.Is(err, target)
+	if matcher, ok := target.(*Matcher); ok {
+		return matcher.Match(err)
+	}
+
+	reportDeprecated(target)
+
+	return errors.Is(err, target) || isDescendant(err, target)
 }
 
 // As behaves like errors.As from the standard library.
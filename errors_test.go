@@ -279,3 +279,44 @@ func TestAsEdge_4(t *testing.T) {
 	assert.True(t, As(ei2, &ti1))
 	assert.EqualValues(t, wi1, ti1)
 }
+
+func TestWrapTree(t *testing.T) {
+	t.Parallel()
+
+	joined := stderrors.Join(io.EOF, io.ErrClosedPipe)
+
+	e1 := New(str).Wrap(joined)
+	assert.True(t, Is(e1, io.EOF))
+	assert.True(t, Is(e1, io.ErrClosedPipe))
+	assert.False(t, Is(e1, io.ErrShortBuffer))
+
+	e2 := e1.Wrap(io.ErrShortBuffer)
+	assert.Equal(t, str+": EOF\nio: read/write on closed pipe: short buffer", e2.Error())
+	assert.True(t, Is(e2, io.EOF))
+	assert.True(t, Is(e2, io.ErrClosedPipe))
+	assert.True(t, Is(e2, io.ErrShortBuffer))
+
+	e3 := New(str).Errorf("multiple: %w, %w", io.EOF, myError("custom"))
+	assert.True(t, Is(e3, io.EOF))
+
+	var target myError
+	require.True(t, As(e3, &target))
+	assert.Equal(t, myError("custom"), target)
+
+	// the tree is explored when it is the topmost error
+	e4 := NewErr(stderrors.Join(io.EOF, myError("head"))).Wrap(io.ErrShortBuffer)
+	require.True(t, As(e4, &target))
+	assert.Equal(t, myError("head"), target)
+	assert.True(t, Is(e4, io.ErrShortBuffer))
+
+	e5 := NewWithCauses(str, io.EOF, nil, myError("custom"))
+	assert.Equal(t, str+": EOF\ncustom", e5.Error())
+	assert.True(t, Is(e5, io.EOF))
+	require.True(t, As(e5, &target))
+
+	e6 := NewWithCauses(str, nil, io.EOF)
+	assert.Equal(t, io.EOF, Unwrap(e6))
+
+	e7 := NewWithCauses(str)
+	assert.Equal(t, str, e7.Error())
+}
@@ -0,0 +1,94 @@
+// Package errslog provides a log/slog handler which expands errors built with wrappable-errors.
+package errslog
+
+import (
+	"context"
+	"log/slog"
+
+	errors "github.com/fredbi/wrappable-errors"
+)
+
+var _ slog.Handler = &Handler{}
+
+// Handler wraps another slog.Handler, and expands any error-valued attribute built from wrappable errors
+// into a group, as rendered by errors.LogValue().
+//
+// Errors from this package are detected anywhere in the chain of errors, e.g. when wrapped with fmt.Errorf().
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler builds a Handler which passes expanded records to the next handler
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled reports whether the next handler handles records at the given level
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle expands the attributes of the record, and passes it to the next handler
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	expanded := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		expanded.AddAttrs(expand(attr))
+
+		return true
+	})
+
+	return h.next.Handle(ctx, expanded)
+}
+
+// WithAttrs returns a Handler with expanded attributes
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	expanded := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		expanded = append(expanded, expand(attr))
+	}
+
+	return &Handler{next: h.next.WithAttrs(expanded)}
+}
+
+// WithGroup returns a Handler with a group
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+func expand(attr slog.Attr) slog.Attr {
+	switch attr.Value.Kind() {
+	case slog.KindAny:
+		err, ok := attr.Value.Any().(error)
+		if !ok {
+			return attr
+		}
+
+		if !fromPackage(err) {
+			return attr
+		}
+
+		return slog.Attr{Key: attr.Key, Value: errors.LogValue(err)}
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		expanded := make([]slog.Attr, 0, len(group))
+		for _, inner := range group {
+			expanded = append(expanded, expand(inner))
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(expanded...)}
+	default:
+		return attr
+	}
+}
+
+// fromPackage tells if a chain of errors holds some error built by wrappable-errors
+func fromPackage(err error) bool {
+	if _, ok := errors.AsType[errors.Wrappable](err); ok {
+		return true
+	}
+
+	// custom error classes are not Wrappable, but know how to render themselves for slog
+	_, ok := errors.AsType[slog.LogValuer](err)
+
+	return ok
+}
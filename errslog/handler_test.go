@@ -0,0 +1,77 @@
+package errslog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	errors "github.com/fredbi/wrappable-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// customError embeds a Wrappable, but is not a slog.LogValuer
+type customError struct {
+	errors.Wrappable
+}
+
+func newLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(NewHandler(slog.NewJSONHandler(buf, nil)))
+}
+
+func decode(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	return record
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf)
+
+	wrappable := errors.New("not found").With("resource", "user").Wrap(io.EOF)
+	logger.Error("failure",
+		"wrapped", fmt.Errorf("handler: %w", wrappable),
+		"custom", customError{Wrappable: errors.New("custom")},
+		"plain", io.EOF,
+		"count", 1,
+		slog.Group("nested", "error", fmt.Errorf("nested: %w", wrappable)),
+	)
+
+	record := decode(t, &buf)
+	assert.Equal(t, map[string]interface{}{
+		"message":    "handler: not found: EOF",
+		"chain":      []interface{}{"handler", "not found", "EOF"},
+		"attributes": map[string]interface{}{"resource": "user"},
+	}, record["wrapped"])
+	assert.Equal(t, map[string]interface{}{"message": "custom"}, record["custom"])
+	assert.Equal(t, "EOF", record["plain"])
+	assert.EqualValues(t, 1, record["count"])
+	assert.Equal(t, "user", record["nested"].(map[string]interface{})["error"].(map[string]interface{})["attributes"].(map[string]interface{})["resource"])
+}
+
+func TestHandlerWith(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf).
+		With("error", fmt.Errorf("with: %w", errors.New("wrappable"))).
+		WithGroup("group")
+
+	assert.True(t, logger.Handler().Enabled(context.Background(), slog.LevelInfo))
+	assert.False(t, logger.Handler().Enabled(context.Background(), slog.LevelDebug))
+
+	logger.Info("message", "key", "value")
+
+	record := decode(t, &buf)
+	assert.Equal(t, map[string]interface{}{
+		"message": "with: wrappable",
+		"chain":   []interface{}{"with", "wrappable"},
+	}, record["error"])
+	assert.Equal(t, map[string]interface{}{"key": "value"}, record["group"])
+}
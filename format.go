@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+)
+
+var (
+	_ fmt.Formatter = wrapped{}
+	_ fmt.Formatter = &stacked{}
+)
+
+// Format implements fmt.Formatter.
+//
+// Supported verbs:
+//
+//	%s, %v: the plain error message, as with Error()
+//	%q:     the quoted error message
+//	%+v:    every error in the stack on its own line, with stack traces, caller locations and return traces whenever available
+//	%#v:    a go-syntax representation of the stack of errors
+func (e wrapped) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			fmt.Fprintf(s, "%+v", e.err)
+			if frame, ok := e.location(); ok {
+				fmt.Fprintf(s, "\n\t%s", frame)
+			}
+			if e.cause != nil {
+				fmt.Fprintf(s, "\n%+v", e.cause)
+			}
+			if e.causePC != 0 {
+				fmt.Fprintf(s, "\n\t%s", frameOf(e.causePC))
+			}
+			if trace, ok := e.ReturnTrace(); ok {
+				fmt.Fprintf(s, "\n%s", trace)
+			}
+
+			return
+		case s.Flag('#'):
+			fmt.Fprintf(s, "&errors.wrapped{err:%#v, cause:%#v}", e.err, e.cause)
+
+			return
+		}
+
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%s)", verb, e.Error())
+	}
+}
+
+// Format implements fmt.Formatter.
+//
+// With the %+v verb, the stack trace is printed after the stack of errors.
+// Other verbs behave like for any other Wrappable.
+func (s *stacked) Format(st fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case st.Flag('+'):
+			fmt.Fprintf(st, "%+v", s.Wrappable)
+			for _, frame := range s.StackTrace() {
+				fmt.Fprintf(st, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+
+			return
+		case st.Flag('#'):
+			fmt.Fprintf(st, "&errors.stacked{Wrappable:%#v, stack:%#v}", s.Wrappable, s.stack)
+
+			return
+		}
+
+		_, _ = io.WriteString(st, s.Error())
+	case 's':
+		_, _ = io.WriteString(st, s.Error())
+	case 'q':
+		fmt.Fprintf(st, "%q", s.Error())
+	default:
+		fmt.Fprintf(st, "%%!%c(%s)", verb, s.Error())
+	}
+}
@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(io.EOF).Errorf("message: %w", io.ErrUnexpectedEOF)
+
+	assert.Equal(t, e.Error(), fmt.Sprintf("%v", e))
+	assert.Equal(t, e.Error(), fmt.Sprintf("%s", e))
+	assert.Equal(t, `"test error: EOF: message: unexpected EOF"`, fmt.Sprintf("%q", e))
+	assert.Equal(t, "test error\nEOF\nmessage: unexpected EOF", fmt.Sprintf("%+v", e))
+	assert.Equal(t,
+		`&errors.wrapped{err:&errors.errorString{s:"test error"}, cause:<nil>}`,
+		fmt.Sprintf("%#v", New(str)),
+	)
+	assert.Equal(t, "%!d(test error)", fmt.Sprintf("%d", New(str)))
+}
+
+func TestFormatStack(t *testing.T) {
+	t.Parallel()
+
+	e := WithStack(New(str).Wrap(io.EOF))
+
+	assert.Equal(t, e.Error(), fmt.Sprintf("%v", e))
+	assert.Equal(t, `"test error: EOF"`, fmt.Sprintf("%q", e))
+
+	lines := strings.Split(fmt.Sprintf("%+v", e), "\n")
+	require.Greater(t, len(lines), 3)
+	assert.Equal(t, []string{str, "EOF"}, lines[:2])
+	assert.True(t, strings.HasSuffix(lines[2], "TestFormatStack"))
+	assert.Contains(t, lines[3], "format_test.go:")
+
+	assert.True(t, strings.HasPrefix(fmt.Sprintf("%#v", e), "&errors.stacked{Wrappable:&errors.wrapped{"))
+
+	// a stack nested in a wrappable error is rendered too
+	nested := New("outer").Wrap(e)
+	assert.Contains(t, fmt.Sprintf("%+v", nested), "TestFormatStack")
+}
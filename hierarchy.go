@@ -0,0 +1,192 @@
+package errors
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ErrInvalidHierarchy is returned by DeclareParent when a declaration is invalid, e.g. when it introduces a cycle
+var ErrInvalidHierarchy = New("wrappable-errors: invalid error hierarchy")
+
+// hierarchy of sentinel errors: parents are indexed by child, and aliases go both ways
+var hierarchy = struct {
+	sync.RWMutex
+
+	parents map[error][]error
+	aliases map[error][]error
+}{
+	parents: make(map[error][]error),
+	aliases: make(map[error][]error),
+}
+
+// DeclareParent declares that a sentinel error is a kind of its parent sentinels.
+//
+// Any error which is the child then matches the parents, and their own ancestors, with Is:
+//
+//	errors.DeclareParent(ErrNotFound, ErrClient)
+//	errors.DeclareParent(ErrUserNotFound, ErrNotFound)
+//
+//	errors.Is(ErrUserNotFound.Wrap(err), ErrClient) // true
+//
+// Errors derived from the child (e.g. child.Wrap(err)) match the parents as well.
+//
+// Errors from this package resolve the hierarchy with errors.Is from the standard library.
+// The Is() function from this package also resolves it for chains which hold only other errors.
+//
+// An error wrapping ErrInvalidHierarchy is returned if the child or a parent is nil or not comparable,
+// or if the declaration introduces a cycle. In that case, no parent is declared.
+func DeclareParent(child error, parents ...error) error {
+	if !isComparableError(child) {
+		return ErrInvalidHierarchy.Errorf("child must be a non-nil comparable error, got %T", child)
+	}
+
+	hierarchy.Lock()
+	defer hierarchy.Unlock()
+
+	for _, parent := range parents {
+		if !isComparableError(parent) {
+			return ErrInvalidHierarchy.Errorf("parent of %q must be a non-nil comparable error, got %T", child, parent)
+		}
+
+		if sameSentinel(parent, child) || inheritsLocked(parent, child) {
+			return ErrInvalidHierarchy.Errorf("declaring %q as a parent of %q introduces a cycle", parent, child)
+		}
+	}
+
+	link(hierarchy.parents, child, parents...)
+
+	return nil
+}
+
+// link adds edges from a sentinel to other sentinels in the hierarchy, skipping duplicates
+func link(edges map[error][]error, from error, to ...error) {
+	keys := []error{from}
+	if head, ok := ownedHead(from); ok {
+		// errors derived from the sentinel retain its topmost error: index it too
+		keys = append(keys, head)
+	}
+
+	for _, key := range keys {
+	EDGES:
+		for _, target := range to {
+			for _, declared := range edges[key] {
+				if sameError(declared, target) {
+					continue EDGES
+				}
+			}
+
+			edges[key] = append(edges[key], target)
+		}
+	}
+}
+
+// Parents returns the parents declared for an error with DeclareParent
+func Parents(err error) []error {
+	if !isComparableError(err) {
+		return nil
+	}
+
+	hierarchy.RLock()
+	defer hierarchy.RUnlock()
+
+	parents := hierarchy.parents[err]
+	if len(parents) == 0 {
+		return nil
+	}
+
+	return append([]error(nil), parents...)
+}
+
+// inherits tells if the target is an ancestor or an alias of a single error in a chain,
+// as declared with DeclareParent, Alias or Deprecate
+func inherits(err, target error) bool {
+	if err == nil || target == nil {
+		return false
+	}
+
+	hierarchy.RLock()
+	defer hierarchy.RUnlock()
+
+	if len(hierarchy.parents) == 0 && len(hierarchy.aliases) == 0 {
+		return false
+	}
+
+	return inheritsLocked(err, target)
+}
+
+func inheritsLocked(err, target error) bool {
+	visited := make(map[error]struct{})
+	pending := []error{err}
+
+	for len(pending) > 0 {
+		current := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if !reflect.ValueOf(current).Comparable() {
+			continue
+		}
+
+		if _, seen := visited[current]; seen {
+			continue
+		}
+		visited[current] = struct{}{}
+
+		for _, edges := range [...][]error{hierarchy.aliases[current], hierarchy.parents[current]} {
+			for _, related := range edges {
+				if sameSentinel(related, target) {
+					return true
+				}
+
+				pending = append(pending, related)
+			}
+		}
+	}
+
+	return false
+}
+
+// isDescendant tells if any error in a chain inherits from the target
+func isDescendant(err, target error) bool {
+	hierarchy.RLock()
+	empty := len(hierarchy.parents) == 0 && len(hierarchy.aliases) == 0
+	hierarchy.RUnlock()
+
+	if empty || err == nil || target == nil {
+		return false
+	}
+
+	var found bool
+
+	walk(err, true, func(node error, _ int, _ []int, _ bool) WalkAction {
+		if inherits(node, target) {
+			found = true
+
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return found
+}
+
+// sameSentinel tells if two errors are the same sentinel, or share the same topmost error
+func sameSentinel(a, b error) bool {
+	if sameError(a, b) {
+		return true
+	}
+
+	if head, ok := ownedHead(a); ok {
+		a = head
+	}
+
+	if head, ok := ownedHead(b); ok {
+		b = head
+	}
+
+	return sameError(a, b)
+}
+
+func isComparableError(err error) bool {
+	return err != nil && reflect.ValueOf(err).Comparable()
+}
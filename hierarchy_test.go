@@ -0,0 +1,108 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeclareParent(t *testing.T) {
+	t.Parallel()
+
+	errClient := New("client error")
+	errNotFound := New("not found")
+	errUserNotFound := New("user not found")
+	errConflict := New("conflict")
+	errUnrelated := New("unrelated")
+	errLeaf := fmt.Errorf("leaf")
+	errBadInput := NewErr(io.EOF)
+
+	require.NoError(t, DeclareParent(errNotFound, errClient))
+	require.NoError(t, DeclareParent(errUserNotFound, errNotFound))
+	require.NoError(t, DeclareParent(errConflict, errClient))
+	require.NoError(t, DeclareParent(errLeaf, io.EOF))
+	require.NoError(t, DeclareParent(errBadInput, errClient))
+
+	for _, tc := range []struct {
+		Title    string
+		Err      error
+		Target   error
+		Expected bool
+	}{
+		{Title: "parent", Err: errNotFound, Target: errClient, Expected: true},
+		{Title: "grand parent", Err: errUserNotFound, Target: errClient, Expected: true},
+		{Title: "derived error", Err: errUserNotFound.Wrap(io.ErrUnexpectedEOF), Target: errClient, Expected: true},
+		{Title: "derived with Errorf", Err: errUserNotFound.Errorf("id %d", 42), Target: errNotFound, Expected: true},
+		{Title: "wrapped error", Err: New("get").Wrap(errUserNotFound), Target: errClient, Expected: true},
+		{Title: "deeply wrapped error", Err: New("get").Wrap(io.EOF).Wrap(errUserNotFound), Target: errNotFound, Expected: true},
+		{Title: "stacked error", Err: WithStack(errUserNotFound), Target: errClient, Expected: true},
+		{Title: "joined error", Err: Join(io.EOF, errConflict), Target: errClient, Expected: true},
+		{Title: "sentinel built from another error", Err: errBadInput, Target: errClient, Expected: true},
+		{Title: "error wrapped by a sentinel", Err: io.EOF, Target: errClient},
+		{Title: "error wrapped by a sentinel, wrapped", Err: fmt.Errorf("reading: %w", io.EOF), Target: errClient},
+		{Title: "sibling", Err: errConflict, Target: errNotFound},
+		{Title: "child", Err: errClient, Target: errNotFound},
+		{Title: "unrelated", Err: errUnrelated.Wrap(io.EOF), Target: errClient},
+		{Title: "self", Err: errUserNotFound, Target: errUserNotFound, Expected: true},
+		{Title: "nil", Err: nil, Target: errClient},
+	} {
+		assert.Equal(t, tc.Expected, Is(tc.Err, tc.Target), tc.Title)
+	}
+
+	t.Run("standard library errors", func(t *testing.T) {
+		err := fmt.Errorf("reading: %w", errLeaf)
+
+		assert.True(t, Is(err, io.EOF))
+		assert.False(t, Is(err, io.ErrUnexpectedEOF))
+
+		// errors from this package resolve the hierarchy with the standard library too
+		assert.True(t, stderrors.Is(New("get").Wrap(errUserNotFound), errClient))
+	})
+
+	t.Run("should match", func(t *testing.T) {
+		var handled string
+
+		Match(errUserNotFound.Wrap(io.EOF)).
+			Is(errConflict, func(error) { handled = "conflict" }).
+			Is(errClient, func(error) { handled = "client" }).
+			Dispatch()
+		assert.Equal(t, "client", handled)
+	})
+
+	t.Run("should return parents", func(t *testing.T) {
+		assert.Equal(t, []error{errClient}, Parents(errNotFound))
+		assert.Empty(t, Parents(errClient))
+		assert.Empty(t, Parents(nil))
+
+		require.NoError(t, DeclareParent(errNotFound, errClient))
+		assert.Len(t, Parents(errNotFound), 1)
+	})
+
+	t.Run("should detect cycles", func(t *testing.T) {
+		err := DeclareParent(errClient, errUserNotFound)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidHierarchy)
+
+		require.ErrorIs(t, DeclareParent(errClient, errClient), ErrInvalidHierarchy)
+		require.ErrorIs(t, DeclareParent(errClient, errUnrelated, errNotFound), ErrInvalidHierarchy)
+		assert.Empty(t, Parents(errClient))
+		assert.False(t, Is(errClient, errUnrelated))
+	})
+
+	t.Run("should reject invalid errors", func(t *testing.T) {
+		require.ErrorIs(t, DeclareParent(nil, errClient), ErrInvalidHierarchy)
+		require.ErrorIs(t, DeclareParent(uncomparableError{}, errClient), ErrInvalidHierarchy)
+		require.ErrorIs(t, DeclareParent(errClient, nil), ErrInvalidHierarchy)
+		require.ErrorIs(t, DeclareParent(errClient, uncomparableError{}), ErrInvalidHierarchy)
+	})
+}
+
+type uncomparableError struct {
+	errs []error
+}
+
+func (uncomparableError) Error() string { return "uncomparable" }
@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// HTTPStatuser is an error which declares the HTTP status it maps to
+type HTTPStatuser interface {
+	error
+
+	HTTPStatus() int
+}
+
+// specificity of a mapping to an HTTP status: registered values are more specific
+// than registered types, which are more specific than declared statuses.
+const (
+	unmapped = iota
+	canonicalStatus
+	declaredStatus
+	typeStatus
+	sentinelStatus
+)
+
+// HTTPStatusMap maps errors to HTTP statuses.
+//
+// Errors map to an HTTP status either because they are registered sentinels, because their type is registered,
+// because they implement HTTPStatuser, or because they resolve to a canonical code (see Status).
+//
+// An HTTPStatusMap is safe for concurrent use.
+type HTTPStatusMap struct {
+	mx            sync.RWMutex
+	defaultStatus int
+	sentinels     map[error]int
+	types         map[reflect.Type]int
+}
+
+// DefaultHTTPStatusMap is the mapping used by the package-level HTTPStatus functions
+var DefaultHTTPStatusMap = NewHTTPStatusMap(http.StatusInternalServerError)
+
+// NewHTTPStatusMap builds an empty mapping, with a default status for errors which are not mapped
+func NewHTTPStatusMap(defaultStatus int) *HTTPStatusMap {
+	return &HTTPStatusMap{
+		defaultStatus: defaultStatus,
+		sentinels:     make(map[error]int),
+		types:         make(map[reflect.Type]int),
+	}
+}
+
+// RegisterHTTPStatus maps a sentinel error to an HTTP status in the DefaultHTTPStatusMap
+func RegisterHTTPStatus(sentinel error, status int) {
+	DefaultHTTPStatusMap.Register(sentinel, status)
+}
+
+// RegisterHTTPStatusClass maps all the errors of the same type as the template to an HTTP status,
+// in the DefaultHTTPStatusMap
+func RegisterHTTPStatusClass(template error, status int) {
+	DefaultHTTPStatusMap.RegisterClass(template, status)
+}
+
+// HTTPStatus returns the HTTP status an error maps to in the DefaultHTTPStatusMap.
+//
+// Like with Status, a nil error yields http.StatusOK.
+func HTTPStatus(err error) int {
+	return DefaultHTTPStatusMap.Status(err)
+}
+
+// SetDefault sets the status returned for errors which are not mapped
+func (m *HTTPStatusMap) SetDefault(status int) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.defaultStatus = status
+}
+
+// Default returns the status returned for errors which are not mapped
+func (m *HTTPStatusMap) Default() int {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	return m.defaultStatus
+}
+
+// Register maps a sentinel error to an HTTP status.
+//
+// Errors derived from the sentinel (e.g. sentinel.Wrap(err)) map to the same status.
+//
+// Register panics if the sentinel is nil or not comparable.
+func (m *HTTPStatusMap) Register(sentinel error, status int) {
+	if sentinel == nil || !reflect.ValueOf(sentinel).Comparable() {
+		panic("wrappable-errors: HTTP status sentinel must be a non-nil comparable error")
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.sentinels[sentinel] = status
+
+	if head, ok := ownedHead(sentinel); ok {
+		m.sentinels[head] = status
+	}
+}
+
+// RegisterClass maps all the errors of the same type as the template to an HTTP status.
+//
+// RegisterClass panics if the template is nil.
+func (m *HTTPStatusMap) RegisterClass(template error, status int) {
+	if template == nil {
+		panic("wrappable-errors: HTTP status class template must be a non-nil error")
+	}
+
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.types[reflect.TypeOf(template)] = status
+}
+
+// Status returns the HTTP status an error maps to, or the default status.
+//
+// A nil error yields http.StatusOK. See Lookup.
+func (m *HTTPStatusMap) Status(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	if status, ok := m.Lookup(err); ok {
+		return status
+	}
+
+	return m.Default()
+}
+
+// Lookup the HTTP status an error maps to, walking the chain like Walk.
+//
+// The most specific mapping found in the chain wins: a registered sentinel value beats a registered type,
+// which beats a status declared by the error with HTTPStatuser, which beats the HTTP status of a canonical code.
+// When several errors have equally specific mappings, the outermost one wins.
+func (m *HTTPStatusMap) Lookup(err error) (int, bool) {
+	var (
+		status int
+		best   = unmapped
+	)
+
+	walk(err, true, func(node error, _ int, _ []int, leaf bool) WalkAction {
+		if candidate, specificity := m.lookupNode(node, leaf); specificity > best {
+			status, best = candidate, specificity
+		}
+
+		if best == sentinelStatus {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return status, best != unmapped
+}
+
+// lookupNode maps a single error in a chain.
+//
+// The map is locked only to read from it: methods of the error may map errors themselves.
+func (m *HTTPStatusMap) lookupNode(err error, leaf bool) (int, int) {
+	if status, specificity := m.registered(err); specificity != unmapped {
+		return status, specificity
+	}
+
+	if declared, ok := err.(HTTPStatuser); ok {
+		return declared.HTTPStatus(), declaredStatus
+	}
+
+	if code, ok := statusOf(err, leaf); ok {
+		return code.HTTPStatus(), canonicalStatus
+	}
+
+	return 0, unmapped
+}
+
+// registered yields the status an error maps to, when its value or its type is registered
+func (m *HTTPStatusMap) registered(err error) (int, int) {
+	m.mx.RLock()
+	defer m.mx.RUnlock()
+
+	if reflect.ValueOf(err).Comparable() {
+		if status, ok := m.sentinels[err]; ok {
+			return status, sentinelStatus
+		}
+	}
+
+	if status, ok := m.types[reflect.TypeOf(err)]; ok {
+		return status, typeStatus
+	}
+
+	return 0, unmapped
+}
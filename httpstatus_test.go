@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type httpStatusTestType struct {
+	Wrappable
+}
+
+type httpStatusTestDeclared struct {
+	Wrappable
+}
+
+func (httpStatusTestDeclared) HTTPStatus() int {
+	return http.StatusTeapot
+}
+
+// httpStatusReentrant maps its cause to an HTTP status, while a registration is pending
+type httpStatusReentrant struct {
+	m     *HTTPStatusMap
+	cause error
+}
+
+func (e httpStatusReentrant) Error() string { return "reentrant" }
+
+func (e httpStatusReentrant) HTTPStatus() int {
+	registered := make(chan struct{})
+	go func() {
+		e.m.Register(New("pending"), http.StatusConflict)
+		close(registered)
+	}()
+	time.Sleep(10 * time.Millisecond) // the registration waits for the lock, if it is held
+
+	status := e.m.Status(e.cause)
+	<-registered
+
+	return status
+}
+
+func TestHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	errNotFound := New("not found")
+	errConflict := New("conflict")
+	errUnmapped := New("unmapped")
+	errTyped := &httpStatusTestType{Wrappable: New("typed")}
+	errDeclared := &httpStatusTestDeclared{Wrappable: New("declared")}
+	errBadInput := NewErr(io.ErrNoProgress)
+
+	m := NewHTTPStatusMap(http.StatusInternalServerError)
+	m.Register(errNotFound, http.StatusNotFound)
+	m.Register(errConflict, http.StatusConflict)
+	m.Register(io.ErrUnexpectedEOF, http.StatusBadRequest)
+	m.Register(errBadInput, http.StatusBadRequest)
+	m.RegisterClass(&httpStatusTestType{}, http.StatusUnprocessableEntity)
+
+	for _, tc := range []struct {
+		Title    string
+		Err      error
+		Expected int
+		Mapped   bool
+	}{
+		{Title: "nil error", Err: nil, Expected: http.StatusOK},
+		{Title: "unmapped error", Err: errUnmapped.Wrap(io.EOF), Expected: http.StatusInternalServerError},
+		{Title: "sentinel", Err: errNotFound, Expected: http.StatusNotFound, Mapped: true},
+		{Title: "wrapping sentinel", Err: errNotFound.Wrap(io.EOF), Expected: http.StatusNotFound, Mapped: true},
+		{Title: "wrapped sentinel", Err: errUnmapped.Wrap(errNotFound), Expected: http.StatusNotFound, Mapped: true},
+		{Title: "stdlib sentinel", Err: fmt.Errorf("reading: %w", io.ErrUnexpectedEOF), Expected: http.StatusBadRequest, Mapped: true},
+		{Title: "sentinel built from another error", Err: errBadInput, Expected: http.StatusBadRequest, Mapped: true},
+		{Title: "error wrapped by a sentinel", Err: fmt.Errorf("reading: %w", io.ErrNoProgress), Expected: http.StatusInternalServerError},
+		{Title: "outermost sentinel wins", Err: errConflict.Wrap(errNotFound), Expected: http.StatusConflict, Mapped: true},
+		{Title: "type", Err: errTyped, Expected: http.StatusUnprocessableEntity, Mapped: true},
+		{Title: "declared", Err: errDeclared, Expected: http.StatusTeapot, Mapped: true},
+		{Title: "type beats declared", Err: errDeclared.Wrap(errTyped), Expected: http.StatusUnprocessableEntity, Mapped: true},
+		{Title: "sentinel beats type", Err: errTyped.Wrap(errNotFound), Expected: http.StatusNotFound, Mapped: true},
+		{Title: "joined", Err: Join(errUnmapped, errDeclared), Expected: http.StatusTeapot, Mapped: true},
+	} {
+		assert.Equal(t, tc.Expected, m.Status(tc.Err), tc.Title)
+
+		status, ok := m.Lookup(tc.Err)
+		assert.Equal(t, tc.Mapped, ok, tc.Title)
+		if ok {
+			assert.Equal(t, tc.Expected, status, tc.Title)
+		}
+	}
+
+	t.Run("should change the default status", func(t *testing.T) {
+		m := NewHTTPStatusMap(http.StatusInternalServerError)
+		m.SetDefault(http.StatusBadGateway)
+		assert.Equal(t, http.StatusBadGateway, m.Default())
+		assert.Equal(t, http.StatusBadGateway, m.Status(io.EOF))
+		assert.Equal(t, http.StatusOK, m.Status(nil))
+	})
+
+	t.Run("should panic on invalid registrations", func(t *testing.T) {
+		assert.Panics(t, func() { m.Register(nil, http.StatusNotFound) })
+		assert.Panics(t, func() { m.RegisterClass(nil, http.StatusNotFound) })
+	})
+}
+
+func TestHTTPStatusReentrant(t *testing.T) {
+	t.Parallel()
+
+	m := NewHTTPStatusMap(http.StatusInternalServerError)
+	errNotFound := New("not found")
+	m.Register(errNotFound, http.StatusNotFound)
+
+	mapped := make(chan int, 1)
+	go func() {
+		mapped <- m.Status(New("outer").Wrap(httpStatusReentrant{m: m, cause: errNotFound}))
+	}()
+
+	select {
+	case status := <-mapped:
+		assert.Equal(t, http.StatusNotFound, status)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Status is blocked")
+	}
+}
+
+func TestHTTPStatusHandler(t *testing.T) {
+	t.Parallel()
+
+	errForbidden := New("forbidden")
+	RegisterHTTPStatus(errForbidden, http.StatusForbidden)
+	RegisterHTTPStatusClass(&httpStatusTestType{}, http.StatusUnprocessableEntity)
+
+	handler := func(err error) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, err.Error(), HTTPStatus(err))
+		}
+	}
+
+	for _, tc := range []struct {
+		Err      error
+		Expected int
+	}{
+		{Err: errForbidden.Errorf("user %d", 42), Expected: http.StatusForbidden},
+		{Err: &httpStatusTestType{Wrappable: New("invalid")}, Expected: http.StatusUnprocessableEntity},
+		{Err: io.EOF, Expected: http.StatusInternalServerError},
+	} {
+		rec := httptest.NewRecorder()
+		handler(tc.Err).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		resp := rec.Result()
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, tc.Expected, resp.StatusCode)
+	}
+}
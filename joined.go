@@ -0,0 +1,168 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	_ Wrappable      = &joined{}
+	_ fmt.Formatter  = &joined{}
+	_ json.Marshaler = &joined{}
+)
+
+// Join returns a Wrappable error that holds all the given errors, like errors.Join() from the standard library.
+//
+// Nil errors are discarded, and errors which have been joined with this package are flattened.
+// Join returns nil if there is no non-nil error to join.
+//
+// The joined errors are printed one per line, like with the standard library.
+func Join(errs ...error) Wrappable {
+	flattened := make([]error, 0, len(errs))
+
+	for _, err := range errs {
+		switch e := err.(type) {
+		case nil:
+		case *joined:
+			flattened = append(flattened, e.errs...)
+		default:
+			flattened = append(flattened, err)
+		}
+	}
+
+	if len(flattened) == 0 {
+		return nil
+	}
+
+	return &joined{errs: flattened}
+}
+
+// Append errors to an error, which may be itself a joined error.
+//
+// This is equivalent to Join(err, more...).
+func Append(err error, more ...error) Wrappable {
+	return Join(append([]error{err}, more...)...)
+}
+
+// joined is a Wrappable holding several errors.
+//
+// joined is assumed to remain immutable: the slice of errors is never modified once built.
+type joined struct {
+	errs []error
+}
+
+// Error implements the error interface: joined errors are printed one per line.
+func (e *joined) Error() string {
+	var b strings.Builder
+
+	for i, err := range e.errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+
+	return b.String()
+}
+
+// Wrap another error. The joined errors remain the topmost error.
+func (e *joined) Wrap(err error) Wrappable {
+	if err == nil {
+		return e
+	}
+
+	return &wrapped{
+		err:   e,
+		cause: err,
+	}
+}
+
+// Errorf wraps a nested error built from the extra message.
+func (e *joined) Errorf(format string, args ...interface{}) Wrappable {
+	return e.Wrap(fmt.Errorf(format, args...))
+}
+
+// Unwrap a joined error: there is no single nested error, so this always returns nil.
+//
+// The joined errors are explored by Is() and As().
+func (e *joined) Unwrap() error {
+	return nil
+}
+
+// Err returns the topmost error, that is, the joined errors as a whole.
+func (e *joined) Err() error {
+	return e
+}
+
+// Is implements errors.Is, exploring every joined error
+func (e *joined) Is(err error) bool {
+	if matcher, ok := err.(*Matcher); ok {
+		return matcher.Match(e)
+	}
+
+	for _, inner := range e.errs {
+		if errors.Is(inner, err) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As implements errors.As, exploring every joined error
+func (e *joined) As(target interface{}) bool {
+	for _, inner := range e.errs {
+		if errors.As(inner, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON produces a JSON document describing every joined error. See FromJSON.
+func (e *joined) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeError(e))
+}
+
+// Format implements fmt.Formatter.
+//
+// With the %+v verb, every joined error is printed with %+v.
+func (e *joined) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		switch {
+		case s.Flag('+'):
+			for i, err := range e.errs {
+				if i > 0 {
+					_, _ = io.WriteString(s, "\n")
+				}
+				fmt.Fprintf(s, "%+v", err)
+			}
+
+			return
+		case s.Flag('#'):
+			_, _ = io.WriteString(s, "&errors.joined{errs:[]error{")
+			for i, err := range e.errs {
+				if i > 0 {
+					_, _ = io.WriteString(s, ", ")
+				}
+				fmt.Fprintf(s, "%#v", err)
+			}
+			_, _ = io.WriteString(s, "}}")
+
+			return
+		}
+
+		_, _ = io.WriteString(s, e.Error())
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%s)", verb, e.Error())
+	}
+}
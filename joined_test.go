@@ -0,0 +1,131 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+	assert.Nil(t, Append(nil))
+
+	e := Join(io.EOF, nil, myError("custom"))
+	require.NotNil(t, e)
+	assert.Equal(t, stderrors.Join(io.EOF, myError("custom")).Error(), e.Error())
+
+	assert.True(t, Is(e, io.EOF))
+	assert.True(t, stderrors.Is(e, io.EOF))
+	assert.False(t, Is(e, io.ErrClosedPipe))
+
+	var target myError
+	require.True(t, As(e, &target))
+	assert.Equal(t, myError("custom"), target)
+	require.True(t, stderrors.As(e, &target))
+
+	assert.Nil(t, e.Unwrap())
+	assert.Equal(t, e, e.Err())
+	assert.Equal(t, io.EOF, Root(e))
+}
+
+func TestJoinFlatten(t *testing.T) {
+	t.Parallel()
+
+	e1 := Join(io.EOF, io.ErrClosedPipe)
+	e2 := Append(e1, io.ErrShortBuffer, nil)
+	e3 := Join(io.ErrUnexpectedEOF, e2)
+
+	assert.Equal(t, []error{io.EOF, io.ErrClosedPipe}, e1.(*joined).errs) // e1 is not altered
+	assert.Equal(t, []error{io.EOF, io.ErrClosedPipe, io.ErrShortBuffer}, e2.(*joined).errs)
+	assert.Equal(t, []error{io.ErrUnexpectedEOF, io.EOF, io.ErrClosedPipe, io.ErrShortBuffer}, e3.(*joined).errs)
+
+	// the standard library joined errors are not flattened
+	std := stderrors.Join(io.EOF, io.ErrClosedPipe)
+	e4 := Append(io.ErrShortBuffer, std)
+	assert.Equal(t, []error{io.ErrShortBuffer, std}, e4.(*joined).errs)
+	assert.True(t, Is(e4, io.ErrClosedPipe))
+
+	e5 := Append(New(str))
+	assert.Equal(t, str, e5.Error())
+}
+
+func TestJoinWrap(t *testing.T) {
+	t.Parallel()
+
+	e := Join(io.EOF, io.ErrClosedPipe)
+	assert.Equal(t, e, e.Wrap(nil))
+
+	w := e.Wrap(io.ErrShortBuffer).Errorf("message: %w", io.ErrUnexpectedEOF)
+	assert.Equal(t, "EOF\nio: read/write on closed pipe: short buffer: message: unexpected EOF", w.Error())
+	assert.Equal(t, e, w.Err())
+	assert.True(t, Is(w, io.EOF))
+	assert.True(t, Is(w, io.ErrClosedPipe))
+	assert.True(t, Is(w, io.ErrShortBuffer))
+	assert.True(t, Is(w, io.ErrUnexpectedEOF))
+	assert.ErrorIs(t, Root(w), io.ErrUnexpectedEOF)
+
+	// joined errors within a stack of errors
+	sentinel := New(str)
+	w2 := sentinel.Wrap(Join(io.EOF, New("inner").Wrap(io.ErrClosedPipe)))
+	assert.True(t, Is(w2, sentinel))
+	assert.True(t, Is(w2, io.ErrClosedPipe))
+	assert.ErrorIs(t, Root(w2), io.EOF)
+}
+
+func TestJoinFormat(t *testing.T) {
+	t.Parallel()
+
+	e := Join(New(str).Wrap(io.EOF), io.ErrClosedPipe)
+
+	assert.Equal(t, e.Error(), fmt.Sprintf("%v", e))
+	assert.Equal(t, fmt.Sprintf("%q", e.Error()), fmt.Sprintf("%q", e))
+	assert.Equal(t, "test error\nEOF\nio: read/write on closed pipe", fmt.Sprintf("%+v", e))
+	assert.Equal(t, `&errors.joined{errs:[]error{&errors.errorString{s:"EOF"}}}`, fmt.Sprintf("%#v", Join(io.EOF)))
+	assert.Equal(t,
+		`&errors.joined{errs:[]error{&errors.errorString{s:"EOF"}, &errors.errorString{s:"unexpected EOF"}}}`,
+		fmt.Sprintf("%#v", Join(io.EOF, io.ErrUnexpectedEOF)),
+	)
+}
+
+func TestJoinJSON(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(Join(io.EOF, New("inner").Wrap(io.ErrClosedPipe))).Wrap(io.ErrShortBuffer)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, io.ErrShortBuffer.Error(), Root(decoded).Error())
+
+	rebuilt, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(buf), string(rebuilt))
+}
+
+func TestJoinMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	e := Join(io.EOF, New("inner").Wrap(io.ErrClosedPipe))
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	wrappedBuf, err := json.Marshal(NewErr(e))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(wrappedBuf), string(buf))
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+}
@@ -0,0 +1,414 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	_ json.Marshaler   = wrapped{}
+	_ json.Unmarshaler = &wrapped{}
+)
+
+// jsonError is the JSON document representing an error in a stack of errors.
+//
+// A document describes the topmost error in the stack, with the rest of the stack nested as its cause.
+//
+// Errors that are not built by this package are described by their message, their go type
+// and any exported field they may carry. Errors which know how to Unwrap() are described with the nested error
+// they unwrap to.
+//
+// Registered sentinels and classes of errors are described by their ID (see Register() and RegisterClass()).
+type jsonError struct {
+	Message    string                     `json:"message"`
+	ID         string                     `json:"id,omitempty"`
+	Class      string                     `json:"class,omitempty"`
+	Type       string                     `json:"type,omitempty"`
+	Fields     map[string]json.RawMessage `json:"fields,omitempty"`
+	Attributes attrList                   `json:"attributes,omitempty"`
+	Stack      StackTrace                 `json:"stack,omitempty"`
+	Caller     *Frame                     `json:"caller,omitempty"`
+	Trace      *ReturnTrace               `json:"trace,omitempty"`
+	Errors     []*jsonError               `json:"errors,omitempty"`
+	Err        *jsonError                 `json:"err,omitempty"`
+	Cause      *jsonError                 `json:"cause,omitempty"`
+}
+
+// FromJSON builds a wrappable error from its JSON representation
+func FromJSON(data []byte) (Wrappable, error) {
+	e := &wrapped{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// MarshalJSON produces a nested JSON document describing the stack of errors.
+//
+// Type information and exported fields of custom error types are retained. Stack traces are rendered,
+// but are not restored when unmarshalling.
+func (e wrapped) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.encode())
+}
+
+// UnmarshalJSON rebuilds a stack of errors from its JSON representation.
+//
+// Decoded errors retain their message and their position in the stack, so Unwrap(), Is() and Root()
+// work as with the original error.
+//
+// Registered sentinels are restored as the actual sentinel values, and registered classes of errors
+// are rebuilt with their exported fields. Other types of errors are not restored.
+func (e *wrapped) UnmarshalJSON(data []byte) error {
+	var doc jsonError
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	switch decoded := doc.decode().(type) {
+	case *wrapped:
+		*e = *decoded
+	default:
+		*e = wrapped{err: decoded}
+	}
+
+	return nil
+}
+
+func (e wrapped) encode() *jsonError {
+	doc := encodeError(e.err)
+	caller, hasCaller := e.location()
+	trace, hasTrace := e.ReturnTrace()
+	if e.cause == nil && len(e.attrs) == 0 && !hasCaller && !hasTrace {
+		return doc
+	}
+
+	if doc.Cause != nil || len(doc.Attributes) > 0 || doc.Caller != nil || doc.Trace != nil {
+		// the head is itself a stack of errors
+		doc = &jsonError{
+			Message: e.err.Error(),
+			Err:     doc,
+		}
+	}
+	doc.Attributes = e.attrs
+	doc.Cause = e.encodeCause()
+	if hasCaller {
+		doc.Caller = &caller
+	}
+	if hasTrace {
+		doc.Trace = &trace
+	}
+
+	return doc
+}
+
+// encodeCause describes the cause with the location which stacked it, if any.
+//
+// Chains describe the locations of their errors themselves.
+func (e wrapped) encodeCause() *jsonError {
+	if e.causePC == 0 {
+		return encodeError(e.cause)
+	}
+
+	return wrapped{err: e.cause, pc: e.causePC}.encode()
+}
+
+func encodeError(err error) *jsonError {
+	if err == nil {
+		return nil
+	}
+
+	if id, ok := sentinelID(err); ok {
+		return &jsonError{
+			Message: err.Error(),
+			ID:      id,
+		}
+	}
+
+	switch e := err.(type) {
+	case *wrapped:
+		return e.encode()
+	case wrapped:
+		return e.encode()
+	case *stacked:
+		doc := encodeError(e.Wrappable)
+		doc.Stack = e.StackTrace()
+
+		return doc
+	case *chain:
+		return e.layer().encode()
+	case *joined:
+		doc := &jsonError{
+			Message: e.Error(),
+			Errors:  make([]*jsonError, 0, len(e.errs)),
+		}
+		for _, inner := range e.errs {
+			doc.Errors = append(doc.Errors, encodeError(inner))
+		}
+
+		return doc
+	case *decoded:
+		return &jsonError{
+			Message: e.msg,
+			ID:      e.id,
+			Class:   e.class,
+			Type:    e.typ,
+			Fields:  e.fields,
+			Err:     encodeError(e.err),
+		}
+	}
+
+	doc := &jsonError{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+		Fields:  customFields(err),
+	}
+	doc.Class, _ = classID(err)
+
+	if class, ok := err.(interface{ classWrappable() Wrappable }); ok {
+		if inner, isWrapped := class.classWrappable().(*wrapped); isWrapped {
+			// custom error class: the inner error knows the location of every layer
+			doc.Err = inner.encode()
+
+			return doc
+		}
+	}
+
+	if wrapper, ok := err.(headTail); ok {
+		// custom error type embedding a Wrappable
+		head := wrapper.Err()
+		cause := wrapper.Unwrap()
+		if sameError(head, cause) {
+			cause = nil
+		}
+		inner := wrapped{err: head, cause: cause}
+		if attributer, ok := err.(Attributer); ok {
+			inner.attrs = attributer.Attributes()
+		}
+		if located, ok := err.(Located); ok {
+			if frame, hasCaller := located.Caller(); hasCaller {
+				inner.origin = &frame
+			}
+		}
+		if traced, ok := err.(tracer); ok {
+			inner.trace = traced.returnTrace()
+		}
+		doc.Err = inner.encode()
+
+		return doc
+	}
+
+	if unwrapper, ok := err.(interface{ Unwrap() error }); ok {
+		doc.Err = encodeError(unwrapper.Unwrap())
+	}
+
+	return doc
+}
+
+func (d *jsonError) decode() error {
+	if d == nil {
+		return nil
+	}
+
+	var layer error
+	switch {
+	case len(d.Errors) > 0:
+		errs := make([]error, 0, len(d.Errors))
+		for _, inner := range d.Errors {
+			errs = append(errs, inner.decode())
+		}
+		layer = Join(errs...)
+	case d.Type == "" && d.ID == "" && d.Err != nil:
+		layer = d.Err.decode()
+	default:
+		layer = d.decodeLayer()
+	}
+
+	if d.Cause == nil && len(d.Attributes) == 0 && d.Caller == nil && d.Trace == nil {
+		return layer
+	}
+
+	return &wrapped{
+		err:         layer,
+		cause:       d.Cause.decode(),
+		attrs:       d.Attributes,
+		origin:      d.Caller,
+		causeOrigin: d.Cause.latestCaller(),
+		trace:       decodedTrace(d.Trace),
+	}
+}
+
+// latestCaller yields the location of the latest layer in a stack of errors, following causes
+func (d *jsonError) latestCaller() *Frame {
+	var latest *Frame
+	for layer := d; layer != nil; layer = layer.Cause {
+		if layer.Caller != nil {
+			latest = layer.Caller
+		}
+	}
+
+	return latest
+}
+
+func (d *jsonError) decodeLayer() error {
+	if sentinel, ok := Lookup(d.ID); ok {
+		if head, owned := ownedHead(sentinel); owned {
+			return head
+		}
+
+		return sentinel
+	}
+
+	if builder, ok := lookupClass(d.Class); ok {
+		var inner Wrappable
+		switch nested := d.Err.decode().(type) {
+		case nil:
+			inner = New(d.Message)
+		case Wrappable:
+			inner = nested
+		default:
+			inner = NewErr(nested)
+		}
+
+		rebuilt := builder(inner)
+		if len(d.Fields) > 0 {
+			// best effort: fields which cannot be restored are ignored
+			buf, _ := json.Marshal(d.Fields)
+			_ = json.Unmarshal(buf, rebuilt)
+		}
+
+		return rebuilt
+	}
+
+	return &decoded{
+		msg:    d.Message,
+		id:     d.ID,
+		class:  d.Class,
+		typ:    d.Type,
+		fields: d.Fields,
+		err:    d.Err.decode(),
+	}
+}
+
+// decoded is an error rebuilt from its JSON representation.
+//
+// It retains the original type information and fields, so it may be encoded again as the original error.
+// This applies to sentinels and classes which are not registered on the decoding side.
+type decoded struct {
+	msg    string
+	id     string
+	class  string
+	typ    string
+	fields map[string]json.RawMessage
+	err    error
+}
+
+func (e *decoded) Error() string {
+	return e.msg
+}
+
+func (e *decoded) Unwrap() error {
+	return e.err
+}
+
+// customFields collects the exported fields of a custom error type, as rendered in JSON.
+//
+// Embedded errors are skipped, since they are already part of the stack of errors.
+func customFields(err error) map[string]json.RawMessage {
+	if _, isWrapped := err.(wrappedIface); isWrapped {
+		return nil
+	}
+
+	val := reflect.Indirect(reflect.ValueOf(err))
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var (
+		buf []byte
+		e   error
+	)
+	if _, isClass := err.(interface{ classWrappable() Wrappable }); isClass {
+		// classes marshal as a whole stack of errors
+		buf, e = json.Marshal(ownFields(val))
+	} else {
+		buf, e = json.Marshal(err)
+	}
+	if e != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if e := json.Unmarshal(buf, &fields); e != nil {
+		return nil
+	}
+
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.Anonymous || !field.Type.Implements(errorType) {
+			continue
+		}
+
+		name := field.Name
+		if tag := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]; tag != "" {
+			name = tag
+		}
+		delete(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return fields
+}
+
+// ownFields copies the exported fields of a struct which are rendered in JSON, without the embedded types
+// which bring methods, such as Class.
+func ownFields(val reflect.Value) interface{} {
+	typ := val.Type()
+	fields := make([]reflect.StructField, 0, typ.NumField())
+	indices := make([]int, 0, typ.NumField())
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous && (field.Type.NumMethod() > 0 || reflect.PointerTo(field.Type).NumMethod() > 0) {
+			continue
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name:      field.Name,
+			Type:      field.Type,
+			Tag:       field.Tag,
+			Anonymous: field.Anonymous,
+		})
+		indices = append(indices, i)
+	}
+
+	copied := reflect.New(reflect.StructOf(fields)).Elem()
+	for j, i := range indices {
+		copied.Field(j).Set(val.Field(i))
+	}
+
+	return copied.Interface()
+}
+
+// sameError compares two errors, without panicking on errors which are not comparable
+func sameError(a, b error) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	if !reflect.ValueOf(a).Comparable() || !reflect.ValueOf(b).Comparable() {
+		return false
+	}
+
+	return a == b
+}
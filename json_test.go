@@ -0,0 +1,121 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonTestError struct {
+	Wrappable
+
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func TestJSON(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).Wrap(io.EOF).Errorf("message: %w", io.ErrUnexpectedEOF)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"message": "test error",
+		"type": "*errors.errorString",
+		"cause": {
+			"message": "EOF",
+			"type": "*errors.errorString",
+			"cause": {
+				"message": "message: unexpected EOF",
+				"type": "*fmt.wrapError",
+				"err": {
+					"message": "unexpected EOF",
+					"type": "*errors.errorString"
+				}
+			}
+		}
+	}`, string(buf))
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, str, decoded.Err().Error())
+	assert.Equal(t, "EOF: message: unexpected EOF", decoded.Unwrap().Error())
+	assert.Equal(t, "unexpected EOF", Root(decoded).Error())
+
+	rebuilt, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.Equal(t, string(buf), string(rebuilt))
+}
+
+func TestJSONNested(t *testing.T) {
+	t.Parallel()
+
+	inner := New("inner").Wrap(io.EOF)
+	e := NewErr(inner).Wrap(io.ErrClosedPipe)
+	require.Equal(t, "inner: EOF: io: read/write on closed pipe", e.Error())
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var decoded wrapped
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, inner.Error(), decoded.Err().Error())
+	assert.Equal(t, io.ErrClosedPipe.Error(), decoded.Root().Error())
+}
+
+func TestJSONCustomFields(t *testing.T) {
+	t.Parallel()
+
+	custom := &jsonTestError{Wrappable: New("custom").Wrap(io.EOF), Code: 42}
+	e := New(str).Wrap(custom)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var doc jsonError
+	require.NoError(t, json.Unmarshal(buf, &doc))
+	require.NotNil(t, doc.Cause)
+	assert.Equal(t, "*errors.jsonTestError", doc.Cause.Type)
+	assert.Equal(t, map[string]json.RawMessage{"code": json.RawMessage("42")}, doc.Cause.Fields)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, "EOF", Root(decoded).Error())
+}
+
+func TestJSONStack(t *testing.T) {
+	t.Parallel()
+
+	e := WithStack(io.EOF)
+
+	buf, err := json.Marshal(New(str).Wrap(e))
+	require.NoError(t, err)
+
+	var doc jsonError
+	require.NoError(t, json.Unmarshal(buf, &doc))
+	require.NotNil(t, doc.Cause)
+	require.NotEmpty(t, doc.Cause.Stack)
+	assert.Equal(t, e.StackTrace()[0], doc.Cause.Stack[0])
+}
+
+func TestJSONInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := FromJSON([]byte(`{"message":`))
+	require.Error(t, err)
+
+	e, err := FromJSON([]byte(`{"message":"plain"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "plain", fmt.Sprint(e))
+}
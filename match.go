@@ -0,0 +1,251 @@
+package errors
+
+import (
+	"reflect"
+)
+
+// Dispatcher dispatches an error to the handler of a matching case.
+//
+// A Dispatcher is built with Match(), cases are declared with Is, As, Code and Kind,
+// and the dispatch is run by Default or Dispatch:
+//
+//	errors.Match(err).
+//		Is(ErrNotFound, func(error) { ... }).
+//		As(&target, func(error) { ... }).
+//		Code(http.StatusConflict, func(error) { ... }).
+//		Default(func(error) { ... })
+//
+// The chain of errors is walked only once, like with Walk, and every error in the chain is checked against all cases.
+//
+// By default, the first declared case which matches any error in the chain wins, like a ladder of if errors.Is() ... else if ...
+// With MostSpecific(), the case which matches the error closest to the root cause wins, i.e. the last error visited
+// when walking the chain like Walk.
+//
+// Unlike with errors.Is and errors.As, errors in the chain are matched one by one: the Is and As methods of errors
+// from this package, which explore their nested errors, are not used since nested errors are visited anyway.
+type Dispatcher struct {
+	err          error
+	mostSpecific bool
+	cases        []*matchCase
+}
+
+// matchCase is a case declared in a Dispatcher
+type matchCase struct {
+	match  func(node error) (matched interface{}, ok bool)
+	bind   func(matched interface{})
+	handle func(error)
+
+	rank    int // visit order of the matched error, or -1
+	matched interface{}
+}
+
+// Match builds a Dispatcher for an error
+func Match(err error) *Dispatcher {
+	return &Dispatcher{err: err}
+}
+
+// FirstMatch tells the dispatcher to pick the first declared case which matches. This is the default.
+func (d *Dispatcher) FirstMatch() *Dispatcher {
+	d.mostSpecific = false
+
+	return d
+}
+
+// MostSpecific tells the dispatcher to pick the case which matches the error closest to the root cause.
+//
+// Errors are ranked in the order they are visited by Walk: the last visited one is the most specific.
+// With a tree of errors (e.g. built with Join), errors in later branches are therefore more specific.
+//
+// When several cases match the same error, the first declared one wins.
+func (d *Dispatcher) MostSpecific() *Dispatcher {
+	d.mostSpecific = true
+
+	return d
+}
+
+// Is declares a case matching errors which are the target error, like with errors.Is.
+//
+// Errors derived from a sentinel (e.g. sentinel.Wrap(err)) match the sentinel, and so do its descendants
+// declared with DeclareParent.
+func (d *Dispatcher) Is(target error, fn func(error)) *Dispatcher {
+	reportDeprecated(target)
+	head, owned := ownedHead(target)
+
+	return d.add(&matchCase{
+		match: func(node error) (interface{}, bool) {
+			return nil, isNode(node, target) || (owned && sameError(node, head))
+		},
+		handle: fn,
+	})
+}
+
+// As declares a case matching errors which may be assigned to the target, like with errors.As.
+//
+// The target is set to the matching error before its handler is called.
+//
+// As panics if target is not a non-nil pointer to either a type that implements error, or to any interface type.
+func (d *Dispatcher) As(target interface{}, fn func(error)) *Dispatcher {
+	val := reflect.ValueOf(target)
+	if target == nil || val.Kind() != reflect.Ptr || val.IsNil() {
+		panic("wrappable-errors: target must be a non-nil pointer")
+	}
+
+	targetType := val.Type().Elem()
+	if targetType.Kind() != reflect.Interface && !targetType.Implements(errorType) {
+		panic("wrappable-errors: *target must be interface or implement error")
+	}
+
+	return d.add(&matchCase{
+		match: func(node error) (interface{}, bool) {
+			if reflect.TypeOf(node).AssignableTo(targetType) {
+				return reflect.ValueOf(node), true
+			}
+
+			if _, isOwn := node.(headTail); isOwn {
+				return nil, false
+			}
+
+			if converter, ok := node.(interface{ As(interface{}) bool }); ok {
+				converted := reflect.New(targetType)
+				if converter.As(converted.Interface()) {
+					// the converted value may be a nil interface
+					return converted.Elem(), true
+				}
+			}
+
+			return nil, false
+		},
+		bind: func(matched interface{}) {
+			val.Elem().Set(matched.(reflect.Value))
+		},
+		handle: fn,
+	})
+}
+
+// Code declares a case matching Coded errors with this code
+func (d *Dispatcher) Code(code int, fn func(error)) *Dispatcher {
+	return d.add(&matchCase{
+		match: func(node error) (interface{}, bool) {
+			coded, ok := node.(Coded)
+
+			return nil, ok && coded.Code() == code
+		},
+		handle: fn,
+	})
+}
+
+// Kind declares a case matching Kinded errors with this kind
+func (d *Dispatcher) Kind(kind string, fn func(error)) *Dispatcher {
+	return d.add(&matchCase{
+		match: func(node error) (interface{}, bool) {
+			kinded, ok := node.(Kinded)
+
+			return nil, ok && kinded.Kind() == kind
+		},
+		handle: fn,
+	})
+}
+
+// Default runs the dispatch, and calls fn with the error when no case matches.
+//
+// Nothing is called for a nil error. It returns true if a case matched.
+func (d *Dispatcher) Default(fn func(error)) bool {
+	if d.Dispatch() {
+		return true
+	}
+
+	if d.err != nil && fn != nil {
+		fn(d.err)
+	}
+
+	return false
+}
+
+// Dispatch runs the dispatch, and returns true if a case matched.
+//
+// The handler of the winning case is called with the dispatched error.
+func (d *Dispatcher) Dispatch() bool {
+	if d.err == nil || len(d.cases) == 0 {
+		return false
+	}
+
+	for _, c := range d.cases {
+		c.rank, c.matched = -1, nil
+	}
+
+	var rank int
+	walk(d.err, true, func(node error, _ int, _ []int, _ bool) WalkAction {
+		for _, c := range d.cases {
+			if c.rank >= 0 && !d.mostSpecific {
+				continue
+			}
+
+			if matched, ok := c.match(node); ok {
+				c.rank, c.matched = rank, matched
+			}
+		}
+		rank++
+
+		if !d.mostSpecific && d.cases[0].rank >= 0 {
+			// no other case may win
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	var winner *matchCase
+	for _, c := range d.cases {
+		if c.rank < 0 {
+			continue
+		}
+
+		if winner == nil || (d.mostSpecific && c.rank > winner.rank) {
+			winner = c
+		}
+
+		if !d.mostSpecific {
+			break
+		}
+	}
+
+	if winner == nil {
+		return false
+	}
+
+	if winner.bind != nil {
+		winner.bind(winner.matched)
+	}
+
+	if winner.handle != nil {
+		winner.handle(d.err)
+	}
+
+	return true
+}
+
+func (d *Dispatcher) add(c *matchCase) *Dispatcher {
+	d.cases = append(d.cases, c)
+
+	return d
+}
+
+// isNode tells if a single error in a chain is the target, without exploring nested errors
+func isNode(node, target error) bool {
+	if matcher, ok := target.(*Matcher); ok {
+		return matcher.matches(node)
+	}
+
+	if sameError(node, target) || inherits(node, target) {
+		return true
+	}
+
+	if _, isOwn := node.(headTail); isOwn {
+		// errors from this package explore their nested errors: these are visited anyway
+		return false
+	}
+
+	matcher, ok := node.(interface{ Is(error) bool })
+
+	return ok && matcher.Is(target)
+}
@@ -0,0 +1,186 @@
+package errors_test
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	errors "github.com/fredbi/wrappable-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nilAsError converts to any interface, leaving the target nil
+type nilAsError struct{}
+
+func (nilAsError) Error() string { return "nil as" }
+
+func (nilAsError) As(interface{}) bool { return true }
+
+func TestMatch(t *testing.T) {
+	err := ErrPkg1.Wrap(io.EOF).Wrap(ErrPkg2) // err1 <- EOF <- err2
+
+	t.Run("first declared case wins", func(t *testing.T) {
+		var handled string
+
+		matched := errors.Match(err).
+			Is(io.ErrUnexpectedEOF, func(error) { handled = "unexpected" }).
+			Is(ErrPkg2, func(error) { handled = "err2" }).
+			Is(ErrPkg1, func(error) { handled = "err1" }).
+			Default(func(error) { handled = "default" })
+
+		assert.True(t, matched)
+		assert.Equal(t, "err2", handled)
+	})
+
+	t.Run("most specific case wins", func(t *testing.T) {
+		var handled string
+
+		matched := errors.Match(err).MostSpecific().
+			Is(ErrPkg1, func(error) { handled = "err1" }).
+			Is(ErrPkg2, func(error) { handled = "err2" }).
+			Is(io.EOF, func(error) { handled = "EOF" }).
+			Dispatch()
+
+		assert.True(t, matched)
+		assert.Equal(t, "err2", handled)
+
+		errors.Match(err).MostSpecific().
+			Is(io.EOF, func(error) { handled = "EOF" }).
+			Is(ErrPkg1, func(error) { handled = "err1" }).
+			Dispatch()
+		assert.Equal(t, "EOF", handled)
+
+		// the last two errors of the chain are ranked too, when the shallower one is declared first
+		errors.Match(errors.New("a").Wrap(io.ErrUnexpectedEOF).Wrap(io.EOF)).MostSpecific().
+			Is(io.ErrUnexpectedEOF, func(error) { handled = "unexpected" }).
+			Is(io.EOF, func(error) { handled = "EOF" }).
+			Dispatch()
+		assert.Equal(t, "EOF", handled)
+
+		errors.Match(err).MostSpecific().FirstMatch().
+			Is(ErrPkg1, func(error) { handled = "err1" }).
+			Is(ErrPkg2, func(error) { handled = "err2" }).
+			Dispatch()
+		assert.Equal(t, "err1", handled)
+	})
+
+	t.Run("should not match the error wrapped by a sentinel", func(t *testing.T) {
+		errBadInput := errors.NewErr(io.ErrUnexpectedEOF)
+
+		assert.True(t, errors.Match(errors.New("read").Wrap(errBadInput)).Is(errBadInput, nil).Dispatch())
+		assert.False(t, errors.Match(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)).Is(errBadInput, nil).Dispatch())
+	})
+
+	t.Run("should default", func(t *testing.T) {
+		var handled error
+
+		matched := errors.Match(err).
+			Is(io.ErrUnexpectedEOF, func(error) { t.Fail() }).
+			Code(404, func(error) { t.Fail() }).
+			Default(func(e error) { handled = e })
+
+		assert.False(t, matched)
+		assert.Equal(t, err, handled)
+
+		assert.False(t, errors.Match(nil).Is(io.EOF, func(error) { t.Fail() }).Default(func(error) { t.Fail() }))
+		assert.False(t, errors.Match(err).Dispatch())
+	})
+
+	t.Run("should match classes with As", func(t *testing.T) {
+		var (
+			target  *MyErrorType
+			other   OtherErrors
+			handled error
+		)
+
+		wrapped := errors.New("outer").Wrap(err.Wrap(ErrOther))
+		matched := errors.Match(wrapped).
+			As(&other, func(e error) { handled = e }).
+			As(&target, func(error) { t.Fail() }).
+			Dispatch()
+
+		require.True(t, matched)
+		assert.Equal(t, wrapped, handled)
+		assert.Equal(t, ErrOther, other)
+
+		matched = errors.Match(wrapped).
+			As(&target, func(error) {}).
+			Dispatch()
+		require.True(t, matched)
+		require.NotNil(t, target)
+		assert.Equal(t, "err1: EOF: err2: other", target.Error())
+	})
+
+	t.Run("should match with custom Is and As methods", func(t *testing.T) {
+		_, errNotExist := os.Open(filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, errNotExist)
+
+		var (
+			pathErr *fs.PathError
+			handled string
+		)
+
+		errors.Match(fmt.Errorf("config: %w", errNotExist)).MostSpecific().
+			As(&pathErr, func(error) { handled = "path" }).
+			Is(fs.ErrNotExist, func(error) { handled = "not exist" }).
+			Dispatch()
+		assert.Equal(t, "not exist", handled)
+
+		errors.Match(errors.New("config").Wrap(errNotExist)).
+			As(&pathErr, func(error) { handled = "path" }).
+			Is(fs.ErrNotExist, func(error) { handled = "not exist" }).
+			Dispatch()
+		assert.Equal(t, "path", handled)
+		require.NotNil(t, pathErr)
+	})
+
+	t.Run("should bind a nil interface set by a custom As method", func(t *testing.T) {
+		var (
+			target  errors.Coded
+			handled bool
+		)
+
+		matched := errors.Match(nilAsError{}).
+			As(&target, func(error) { handled = true }).
+			Dispatch()
+		assert.True(t, matched)
+		assert.True(t, handled)
+		assert.Nil(t, target)
+	})
+
+	t.Run("should match codes and kinds", func(t *testing.T) {
+		var handled string
+
+		coded := errors.NewCoded(404, "not found").Wrap(errors.NewKinded("db", "query failed"))
+		errors.Match(coded).
+			Code(500, func(error) { handled = "500" }).
+			Kind("db", func(error) { handled = "db" }).
+			Code(404, func(error) { handled = "404" }).
+			Dispatch()
+		assert.Equal(t, "db", handled)
+
+		errors.Match(coded).MostSpecific().
+			Code(404, func(error) { handled = "404" }).
+			Kind("db", func(error) { handled = "db" }).
+			Dispatch()
+		assert.Equal(t, "db", handled)
+	})
+
+	t.Run("should match joined errors", func(t *testing.T) {
+		var handled string
+
+		errors.Match(errors.Join(io.EOF, ErrPkg2)).
+			Is(ErrPkg2, func(error) { handled = "err2" }).
+			Dispatch()
+		assert.Equal(t, "err2", handled)
+	})
+
+	t.Run("should panic on invalid target", func(t *testing.T) {
+		assert.Panics(t, func() { errors.Match(err).As(nil, nil) })
+		assert.Panics(t, func() { errors.Match(err).As(new(int), nil) })
+	})
+}
@@ -0,0 +1,121 @@
+package errors
+
+// Matcher is an error built from a predicate, which may be used as the target of Is.
+//
+// Is(err, matcher) tells if any error in the chain or tree of errors satisfies the predicate.
+// This also works with errors.Is from the standard library, whenever the chain holds errors from this package.
+//
+// Matchers may be combined with AnyOf, AllOf and Not. Combinators apply to every single error in the chain:
+// Is(err, AllOf(a, b)) tells if some error in the chain satisfies both a and b, and Is(err, Not(a)) tells if
+// some error in the chain does not satisfy a. Use !Is(err, a) to check that no error in the chain satisfies a.
+type Matcher struct {
+	description string
+	predicate   func(error) bool
+}
+
+// MatchFunc builds a Matcher from a predicate on a single error.
+//
+// The description is used as the message of the Matcher.
+func MatchFunc(description string, predicate func(error) bool) *Matcher {
+	return &Matcher{description: description, predicate: predicate}
+}
+
+// MatchType builds a Matcher from a predicate on errors of type T
+func MatchType[T error](description string, predicate func(T) bool) *Matcher {
+	return MatchFunc(description, func(err error) bool {
+		target, ok := err.(T)
+
+		return ok && (predicate == nil || predicate(target))
+	})
+}
+
+// AnyOf builds a Matcher for errors which satisfy any of the matchers
+func AnyOf(matchers ...*Matcher) *Matcher {
+	return &Matcher{
+		description: describe("any of", matchers),
+		predicate: func(err error) bool {
+			for _, m := range matchers {
+				if m.matches(err) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// AllOf builds a Matcher for errors which satisfy all the matchers
+func AllOf(matchers ...*Matcher) *Matcher {
+	return &Matcher{
+		description: describe("all of", matchers),
+		predicate: func(err error) bool {
+			for _, m := range matchers {
+				if !m.matches(err) {
+					return false
+				}
+			}
+
+			return len(matchers) > 0
+		},
+	}
+}
+
+// Not builds a Matcher for errors which do not satisfy the matcher
+func Not(matcher *Matcher) *Matcher {
+	return &Matcher{
+		description: "not " + matcher.Error(),
+		predicate: func(err error) bool {
+			return !matcher.matches(err)
+		},
+	}
+}
+
+// Error returns the description of the Matcher
+func (m *Matcher) Error() string {
+	if m == nil {
+		return "<nil matcher>"
+	}
+
+	return m.description
+}
+
+// Match tells if any error in a chain or tree of errors satisfies the Matcher, exploring the errors like Walk.
+//
+// This is equivalent to Is(err, m).
+func (m *Matcher) Match(err error) bool {
+	if err == nil || m == nil {
+		return false
+	}
+
+	var found bool
+
+	walk(err, true, func(node error, _ int, _ []int, _ bool) WalkAction {
+		if m.matches(node) {
+			found = true
+
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return found
+}
+
+// matches tells if a single error satisfies the Matcher
+func (m *Matcher) matches(err error) bool {
+	return m != nil && m.predicate != nil && m.predicate(err)
+}
+
+func describe(combinator string, matchers []*Matcher) string {
+	description := combinator + " ("
+	for i, m := range matchers {
+		if i > 0 {
+			description += ", "
+		}
+		description += m.Error()
+	}
+
+	return description + ")"
+}
@@ -0,0 +1,91 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+
+	serverError := MatchFunc("5xx", func(err error) bool {
+		coded, ok := err.(Coded)
+
+		return ok && coded.Code() >= 500 && coded.Code() < 600
+	})
+	etcPath := MatchType("path in /etc", func(err *fs.PathError) bool {
+		return strings.HasPrefix(err.Path, "/etc/")
+	})
+	eof := MatchFunc("EOF", func(err error) bool { return err == io.EOF })
+	etcErr := &fs.PathError{Op: "open", Path: "/etc/passwd", Err: fs.ErrPermission}
+	tmpErr := &fs.PathError{Op: "open", Path: "/tmp/file", Err: fs.ErrNotExist}
+
+	for _, tc := range []struct {
+		Title    string
+		Err      error
+		Matcher  *Matcher
+		Expected bool
+	}{
+		{Title: "code", Err: NewCoded(503, "unavailable"), Matcher: serverError, Expected: true},
+		{Title: "wrapped code", Err: New("call").Wrap(NewCoded(502, "bad gateway")), Matcher: serverError, Expected: true},
+		{Title: "other code", Err: NewCoded(404, "not found").Wrap(io.EOF), Matcher: serverError},
+		{Title: "type", Err: New("config").Wrap(etcErr), Matcher: etcPath, Expected: true},
+		{Title: "type in foreign chain", Err: fmt.Errorf("config: %w", etcErr), Matcher: etcPath, Expected: true},
+		{Title: "type not matching", Err: New("config").Wrap(tmpErr), Matcher: etcPath},
+		{Title: "joined", Err: Join(io.ErrUnexpectedEOF, NewCoded(500, "internal")), Matcher: serverError, Expected: true},
+		{Title: "stacked", Err: WithStack(NewCoded(500, "internal")), Matcher: serverError, Expected: true},
+		{Title: "any of", Err: New("config").Wrap(etcErr), Matcher: AnyOf(serverError, etcPath), Expected: true},
+		{Title: "any of none", Err: New("config").Wrap(tmpErr), Matcher: AnyOf(serverError, etcPath)},
+		{Title: "all of on the same error", Err: New("config").Wrap(etcErr), Matcher: AllOf(etcPath, MatchType[*fs.PathError]("path", nil)), Expected: true},
+		{Title: "all of across errors", Err: NewCoded(500, "internal").Wrap(etcErr), Matcher: AllOf(serverError, etcPath)},
+		{Title: "empty all of", Err: io.EOF, Matcher: AllOf()},
+		{Title: "not", Err: NewErr(io.EOF), Matcher: Not(eof), Expected: true},
+		{Title: "not on a single error", Err: io.EOF, Matcher: Not(eof)},
+		{Title: "nil error", Err: nil, Matcher: Not(eof)},
+		{Title: "nil predicate", Err: io.EOF, Matcher: MatchFunc("nil", nil)},
+	} {
+		assert.Equal(t, tc.Expected, Is(tc.Err, tc.Matcher), tc.Title)
+		assert.Equal(t, tc.Expected, tc.Matcher.Match(tc.Err), tc.Title)
+
+		// the standard library relies on the Is() method of errors from this package
+		if _, isOwn := tc.Err.(headTail); isOwn {
+			assert.Equal(t, tc.Expected, stderrors.Is(tc.Err, tc.Matcher), "standard library Is: %s", tc.Title)
+		}
+	}
+
+	t.Run("should work with classes", func(t *testing.T) {
+		type classError struct {
+			Class[classError]
+		}
+
+		errClass := NewOf[classError]("class")
+		isClass := MatchType[*classError]("class", nil)
+
+		assert.True(t, Is(errClass.Wrap(io.EOF), isClass))
+		assert.True(t, stderrors.Is(errClass.Wrap(io.EOF), isClass))
+		assert.True(t, stderrors.Is(errClass.Wrap(io.EOF), eof))
+		assert.False(t, stderrors.Is(errClass, eof))
+	})
+
+	t.Run("should dispatch", func(t *testing.T) {
+		var handled string
+
+		Match(New("call").Wrap(NewCoded(503, "unavailable"))).
+			Is(etcPath, func(error) { handled = "etc" }).
+			Is(serverError, func(error) { handled = "5xx" }).
+			Dispatch()
+		assert.Equal(t, "5xx", handled)
+	})
+
+	t.Run("should describe", func(t *testing.T) {
+		assert.Equal(t, "not any of (5xx, all of (path in /etc, EOF))", Not(AnyOf(serverError, AllOf(etcPath, eof))).Error())
+		assert.Equal(t, "<nil matcher>", (*Matcher)(nil).Error())
+		assert.False(t, (*Matcher)(nil).Match(io.EOF))
+	})
+}
@@ -0,0 +1,368 @@
+// Package problem renders wrappable errors as HTTP problem details (RFC 9457), and parses them back.
+//
+// Registered sentinel errors (see errors.Register()) are rendered with their ID as the problem type,
+// so a client may rehydrate the problem into an error which matches the sentinel with errors.Is().
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	errors "github.com/fredbi/wrappable-errors"
+)
+
+const (
+	// ContentType is the media type of problem details
+	ContentType = "application/problem+json"
+
+	// DefaultType is the problem type used when no registered sentinel is found
+	DefaultType = "about:blank"
+
+	// InstanceKey is the key of the error attribute rendered as the problem instance
+	InstanceKey = "instance"
+
+	// ChainKey is the extension member holding the full chain of errors, when enabled
+	ChainKey = "chain"
+)
+
+// ErrProblem is returned when a response cannot be parsed as problem details
+var ErrProblem = errors.New("invalid problem details")
+
+var _ error = &Details{}
+
+// Details of a problem, as defined by RFC 9457.
+//
+// Details is also an error, which unwraps to the sentinel identified by the problem type, or to the
+// chain of errors carried by the problem.
+type Details struct {
+	Type       string
+	Title      string
+	Status     int
+	Detail     string
+	Instance   string
+	Extensions map[string]interface{}
+
+	err error
+}
+
+// Error returns the detail of the problem, or its title
+func (d *Details) Error() string {
+	if d.Detail != "" {
+		return d.Detail
+	}
+
+	return d.Title
+}
+
+// Unwrap returns the error carried by the problem, if any
+func (d *Details) Unwrap() error {
+	return d.err
+}
+
+// Code returns the HTTP status of the problem. See HTTPStatus.
+func (d *Details) Code() int {
+	return d.HTTPStatus()
+}
+
+// HTTPStatus returns the HTTP status of the problem.
+//
+// A problem without a status is reported as http.StatusInternalServerError.
+func (d *Details) HTTPStatus() int {
+	if d.Status == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return d.Status
+}
+
+var standardMembers = map[string]struct{}{
+	"type": {}, "title": {}, "status": {}, "detail": {}, "instance": {},
+}
+
+// MarshalJSON renders the problem, with extension members at the top level
+func (d Details) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(d.Extensions)+5)
+	for key, value := range d.Extensions {
+		if _, isStandard := standardMembers[key]; isStandard {
+			continue
+		}
+		members[key] = value
+	}
+
+	if d.Type != "" {
+		members["type"] = d.Type
+	}
+	if d.Title != "" {
+		members["title"] = d.Title
+	}
+	if d.Status != 0 {
+		members["status"] = d.Status
+	}
+	if d.Detail != "" {
+		members["detail"] = d.Detail
+	}
+	if d.Instance != "" {
+		members["instance"] = d.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON parses a problem, collecting extension members
+func (d *Details) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	var parsed Details
+	for key, raw := range members {
+		var target interface{}
+		switch key {
+		case "type":
+			target = &parsed.Type
+		case "title":
+			target = &parsed.Title
+		case "status":
+			target = &parsed.Status
+		case "detail":
+			target = &parsed.Detail
+		case "instance":
+			target = &parsed.Instance
+		default:
+			if parsed.Extensions == nil {
+				parsed.Extensions = make(map[string]interface{})
+			}
+
+			var value interface{}
+			if key == ChainKey {
+				value = raw
+			} else if err := json.Unmarshal(raw, &value); err != nil {
+				return err
+			}
+			parsed.Extensions[key] = value
+
+			continue
+		}
+
+		if err := json.Unmarshal(raw, target); err != nil {
+			return ErrProblem.Errorf("invalid member %q: %w", key, err)
+		}
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+// Codec renders errors as problem details, and parses them back.
+type Codec struct {
+	// TypeBase is the prefix of problem types, prepended to the ID of registered sentinels,
+	// e.g. "https://example.com/problems/".
+	TypeBase string
+
+	// Statuses maps errors to HTTP statuses. Defaults to errors.DefaultHTTPStatusMap.
+	Statuses *errors.HTTPStatusMap
+
+	// IncludeDetail renders the full error message as the detail member.
+	//
+	// This exposes the messages of all the errors in the chain to clients.
+	IncludeDetail bool
+
+	// IncludeChain adds the full chain of errors as the "chain" extension member.
+	//
+	// This exposes all the errors in the chain to clients.
+	IncludeChain bool
+}
+
+// DefaultCodec is used by the package-level functions
+var DefaultCodec = Codec{}
+
+// New renders an error as problem details with the DefaultCodec
+func New(err error) *Details {
+	return DefaultCodec.New(err)
+}
+
+// Parse problem details with the DefaultCodec
+func Parse(data []byte) (errors.Wrappable, error) {
+	return DefaultCodec.Parse(data)
+}
+
+// Write an error as a problem details response with the DefaultCodec
+func Write(w http.ResponseWriter, err error) error {
+	return DefaultCodec.Write(w, err)
+}
+
+// ReadResponse parses a problem details response with the DefaultCodec
+func ReadResponse(resp *http.Response) (errors.Wrappable, error) {
+	return DefaultCodec.ReadResponse(resp)
+}
+
+// New renders an error as problem details.
+//
+//   - type: the ID of the outermost registered sentinel in the chain, prefixed with TypeBase, or "about:blank"
+//   - title: the message of this sentinel, or the text of the HTTP status
+//   - status: the HTTP status the error maps to (see errors.HTTPStatusMap), else the outermost code carried
+//     by the chain if it is an HTTP error status (4xx or 5xx), else the default status of the mapping.
+//     Like with errors.HTTPStatus, a nil error yields http.StatusOK
+//   - detail: the error message, if IncludeDetail is enabled
+//   - instance: the "instance" attribute of the error, if any
+//   - extension members: the attributes of the error (see errors.Attributes())
+func (c Codec) New(err error) *Details {
+	statuses := c.Statuses
+	if statuses == nil {
+		statuses = errors.DefaultHTTPStatusMap
+	}
+
+	d := &Details{
+		Type: DefaultType,
+		err:  err,
+	}
+
+	if err == nil {
+		d.Status = statuses.Status(nil)
+		d.Title = http.StatusText(d.Status)
+
+		return d
+	}
+
+	if c.IncludeDetail {
+		d.Detail = err.Error()
+	}
+
+	d.Status = statuses.Default()
+	if status, ok := statuses.Lookup(err); ok {
+		d.Status = status
+	} else if code, ok := errors.Code(err); ok && code >= 400 && code <= 599 {
+		d.Status = code
+	}
+
+	for node := range errors.Nodes(err) {
+		id, ok := errors.ID(node.Err)
+		if !ok {
+			continue
+		}
+
+		d.Type = c.TypeBase + id
+		if sentinel, ok := errors.Lookup(id); ok {
+			d.Title = sentinel.Error()
+		}
+
+		break
+	}
+
+	if d.Title == "" {
+		d.Title = http.StatusText(d.Status)
+	}
+
+	for _, attr := range errors.Attributes(err) {
+		if attr.Key == InstanceKey {
+			d.Instance = fmt.Sprint(attr.Value)
+
+			continue
+		}
+
+		if d.Extensions == nil {
+			d.Extensions = make(map[string]interface{})
+		}
+		d.Extensions[attr.Key] = attr.Value
+	}
+
+	if c.IncludeChain {
+		if chain, e := json.Marshal(errors.NewErr(err)); e == nil {
+			if d.Extensions == nil {
+				d.Extensions = make(map[string]interface{})
+			}
+			d.Extensions[ChainKey] = json.RawMessage(chain)
+		}
+	}
+
+	return d
+}
+
+// Parse problem details into an error.
+//
+// The returned error holds the *Details, which unwraps to the registered sentinel identified by the problem type,
+// or to the chain of errors carried by the "chain" extension member. Extension members are restored as attributes.
+func (c Codec) Parse(data []byte) (errors.Wrappable, error) {
+	return c.parse(data, 0)
+}
+
+// parse problem details, with the status to use when the "status" member is missing
+func (c Codec) parse(data []byte, status int) (errors.Wrappable, error) {
+	var d Details
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, ErrProblem.Wrap(err)
+	}
+
+	if d.Status == 0 {
+		d.Status = status
+	}
+
+	if raw, ok := d.Extensions[ChainKey].(json.RawMessage); ok {
+		chain, err := errors.FromJSON(raw)
+		if err != nil {
+			return nil, ErrProblem.Wrap(err)
+		}
+		d.err = chain
+		delete(d.Extensions, ChainKey)
+	} else if id, ok := strings.CutPrefix(d.Type, c.TypeBase); ok && d.Type != DefaultType {
+		if sentinel, ok := errors.Lookup(id); ok {
+			d.err = sentinel
+		}
+	}
+
+	attrs := make([]interface{}, 0, 2*len(d.Extensions)+2)
+	if d.Instance != "" {
+		attrs = append(attrs, InstanceKey, d.Instance)
+	}
+	for key, value := range d.Extensions {
+		attrs = append(attrs, key, value)
+	}
+
+	return errors.NewErr(&d).With(attrs...), nil
+}
+
+// Write an error as a problem details response
+func (c Codec) Write(w http.ResponseWriter, err error) error {
+	d := c.New(err)
+
+	body, e := json.Marshal(d)
+	if e != nil {
+		return e
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(d.HTTPStatus())
+	_, e = w.Write(body)
+
+	return e
+}
+
+// ReadResponse parses a problem details response.
+//
+// The status code of the response is used when the problem has no "status" member.
+// An error wrapping ErrProblem is returned when the response is not a problem details response.
+func (c Codec) ReadResponse(resp *http.Response) (errors.Wrappable, error) {
+	mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != ContentType {
+		return nil, ErrProblem.Errorf("unexpected content type: %q", resp.Header.Get("Content-Type"))
+	}
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, resp.Body); err != nil {
+		return nil, ErrProblem.Wrap(err)
+	}
+
+	problem, err := c.parse(buf.Bytes(), resp.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return problem, nil
+}
@@ -0,0 +1,215 @@
+package problem
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errors "github.com/fredbi/wrappable-errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNotFound = errors.NewCoded(http.StatusNotFound, "resource not found")
+
+func init() {
+	errors.Register("test.problem.not-found", errNotFound)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("with registered sentinel", func(t *testing.T) {
+		err := errNotFound.
+			With("resource", "user", InstanceKey, "/users/42").
+			Wrap(io.EOF)
+
+		d := Codec{TypeBase: "https://example.com/problems/"}.New(err)
+		assert.Equal(t, "https://example.com/problems/test.problem.not-found", d.Type)
+		assert.Equal(t, "resource not found", d.Title)
+		assert.Equal(t, http.StatusNotFound, d.Status)
+		assert.Empty(t, d.Detail)
+		assert.Equal(t, "/users/42", d.Instance)
+		assert.Equal(t, map[string]interface{}{"resource": "user"}, d.Extensions)
+		assert.ErrorIs(t, d, io.EOF)
+	})
+
+	t.Run("with unregistered error", func(t *testing.T) {
+		d := New(io.EOF)
+		assert.Equal(t, DefaultType, d.Type)
+		assert.Equal(t, "Internal Server Error", d.Title)
+		assert.Equal(t, http.StatusInternalServerError, d.Status)
+		assert.Empty(t, d.Detail)
+		assert.Empty(t, d.Instance)
+		assert.Empty(t, d.Extensions)
+	})
+
+	t.Run("with full detail", func(t *testing.T) {
+		d := Codec{IncludeDetail: true}.New(errNotFound.Wrap(io.EOF))
+		assert.Equal(t, "resource not found", d.Title)
+		assert.Equal(t, "resource not found: EOF", d.Detail)
+		assert.Equal(t, "resource not found: EOF", d.Error())
+	})
+
+	t.Run("with nil error", func(t *testing.T) {
+		d := New(nil)
+		assert.Equal(t, DefaultType, d.Type)
+		assert.Equal(t, "OK", d.Title)
+		assert.Equal(t, errors.HTTPStatus(nil), d.Status)
+		assert.Equal(t, http.StatusOK, d.Status)
+		assert.Empty(t, d.Detail)
+	})
+}
+
+func TestNewWithStatuses(t *testing.T) {
+	errConflict := errors.New("conflict")
+	statuses := errors.NewHTTPStatusMap(http.StatusBadGateway)
+	statuses.Register(errConflict, http.StatusConflict)
+	codec := Codec{Statuses: statuses}
+
+	assert.Equal(t, http.StatusConflict, codec.New(errConflict.Wrap(io.EOF)).Status)
+	assert.Equal(t, http.StatusNotFound, codec.New(errNotFound).Status)
+	assert.Equal(t, http.StatusBadGateway, codec.New(io.EOF).Status)
+	assert.Equal(t, http.StatusTeapot, codec.New(errors.NewCoded(http.StatusTeapot, "teapot")).Status)
+
+	// codes which are not HTTP error statuses are ignored
+	assert.Equal(t, http.StatusBadGateway, codec.New(errors.NewCoded(http.StatusNoContent, "empty")).Status)
+	assert.Equal(t, http.StatusBadGateway, codec.New(errors.NewCoded(http.StatusNotModified, "cached")).Status)
+
+	parsed, err := codec.Parse([]byte(`{"status":409}`))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, statuses.Status(parsed))
+}
+
+func TestDetailsJSON(t *testing.T) {
+	d := Details{
+		Type:       "about:blank",
+		Title:      "Not Found",
+		Status:     http.StatusNotFound,
+		Extensions: map[string]interface{}{"resource": "user", "title": "ignored"},
+	}
+
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"resource":"user"}`, string(data))
+
+	var parsed Details
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, "Not Found", parsed.Title)
+	assert.Equal(t, http.StatusNotFound, parsed.Status)
+	assert.Equal(t, map[string]interface{}{"resource": "user"}, parsed.Extensions)
+
+	require.Error(t, json.Unmarshal([]byte(`{"status":"404"}`), &parsed))
+}
+
+func TestParse(t *testing.T) {
+	t.Run("should rehydrate the registered sentinel", func(t *testing.T) {
+		codec := Codec{TypeBase: "https://example.com/problems/"}
+		data, err := json.Marshal(codec.New(errNotFound.With(InstanceKey, "/users/42", "resource", "user").Wrap(io.EOF)))
+		require.NoError(t, err)
+
+		parsed, err := codec.Parse(data)
+		require.NoError(t, err)
+		assert.ErrorIs(t, parsed, errNotFound)
+		assert.NotErrorIs(t, parsed, io.EOF)
+		assert.Equal(t, "resource not found", parsed.Error())
+		assert.NotContains(t, string(data), "EOF")
+
+		code, ok := errors.Code(parsed)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		attrs := errors.Attributes(parsed)
+		assert.ElementsMatch(t, []errors.Attr{
+			{Key: InstanceKey, Value: "/users/42"},
+			{Key: "resource", Value: "user"},
+		}, attrs)
+
+		var d *Details
+		require.True(t, errors.As(parsed, &d))
+		assert.Equal(t, "/users/42", d.Instance)
+	})
+
+	t.Run("should ignore unknown types", func(t *testing.T) {
+		parsed, err := Parse([]byte(`{"type":"https://example.com/unknown","title":"Teapot","status":418}`))
+		require.NoError(t, err)
+		assert.Equal(t, "Teapot", parsed.Error())
+		assert.NotErrorIs(t, parsed, errNotFound)
+	})
+
+	t.Run("should rehydrate the full chain", func(t *testing.T) {
+		codec := Codec{IncludeChain: true, IncludeDetail: true}
+		data, err := json.Marshal(codec.New(errNotFound.Wrap(io.ErrShortWrite)))
+		require.NoError(t, err)
+
+		parsed, err := codec.Parse(data)
+		require.NoError(t, err)
+		assert.ErrorIs(t, parsed, errNotFound)
+		assert.Equal(t, "resource not found: short write", parsed.Error())
+
+		var d *Details
+		require.True(t, errors.As(parsed, &d))
+		assert.NotContains(t, d.Extensions, ChainKey)
+	})
+
+	t.Run("should not report a missing status", func(t *testing.T) {
+		parsed, err := Parse([]byte(`{"title":"Not Found"}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusInternalServerError, errors.HTTPStatus(parsed))
+
+		code, ok := errors.Code(parsed)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusInternalServerError, code)
+	})
+
+	t.Run("should fail on invalid JSON", func(t *testing.T) {
+		_, err := Parse([]byte(`[]`))
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrProblem)
+	})
+}
+
+func TestHTTP(t *testing.T) {
+	codec := Codec{TypeBase: "/problems/"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		require.NoError(t, codec.Write(w, errNotFound.Errorf("user %d", 42)))
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+
+	parsed, err := codec.ReadResponse(resp)
+	require.NoError(t, err)
+	assert.ErrorIs(t, parsed, errNotFound)
+	assert.Equal(t, "resource not found", parsed.Error())
+
+	t.Run("should default to the status of the response", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", ContentType)
+		rec.WriteHeader(http.StatusNotFound)
+		_, _ = rec.WriteString(`{"title":"Not Found"}`)
+
+		parsed, err := ReadResponse(rec.Result())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, errors.HTTPStatus(parsed))
+
+		var d *Details
+		require.True(t, errors.As(parsed, &d))
+		assert.Equal(t, http.StatusNotFound, d.Status)
+	})
+
+	t.Run("should reject other content types", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		rec.Header().Set("Content-Type", "application/json")
+		rec.WriteHeader(http.StatusNotFound)
+
+		_, err := ReadResponse(rec.Result())
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrProblem)
+	})
+}
@@ -0,0 +1,195 @@
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ClassBuilder rehydrates an error of a registered class.
+//
+// The builder is passed the decoded stack of errors carried by the original error.
+// Exported fields of the original error are unmarshalled into the built error afterwards.
+type ClassBuilder func(inner Wrappable) error
+
+// registry of sentinel errors and error classes, indexed by stable IDs
+var registry = struct {
+	sync.RWMutex
+
+	sentinels   map[string]error
+	sentinelIDs map[error]string
+	classes     map[string]ClassBuilder
+	classIDs    map[reflect.Type]string
+}{
+	sentinels:   make(map[string]error),
+	sentinelIDs: make(map[error]string),
+	classes:     make(map[string]ClassBuilder),
+	classIDs:    make(map[reflect.Type]string),
+}
+
+// Register a sentinel error under a stable ID.
+//
+// Registered sentinels are encoded with their ID, so decoders in this package may
+// rehydrate them into the actual sentinel value: errors.Is() then works on decoded errors
+// just like with the original ones.
+//
+// Register panics if the ID is empty or already registered, or if the sentinel is nil or not comparable.
+// It is intended to be called at package initialization time.
+func Register(id string, sentinel error) {
+	if id == "" {
+		panic("wrappable-errors: empty sentinel ID")
+	}
+
+	if sentinel == nil || !reflect.ValueOf(sentinel).Comparable() {
+		panic(fmt.Sprintf("wrappable-errors: sentinel %q must be a non-nil comparable error", id))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.sentinels[id]; exists {
+		panic(fmt.Sprintf("wrappable-errors: sentinel %q is already registered", id))
+	}
+
+	registry.sentinels[id] = sentinel
+	registry.sentinelIDs[sentinel] = id
+
+	// wrapping a sentinel retains its topmost error: index it too, so wrapped errors are recognized
+	if head, ok := ownedHead(sentinel); ok {
+		registry.sentinelIDs[head] = id
+	}
+}
+
+// RegisterClass registers a class of errors under a stable ID.
+//
+// The class is determined by the type of the error returned by the builder.
+// Errors of this type are encoded with the class ID, and decoders in this package
+// use the builder to rehydrate them.
+//
+// RegisterClass panics if the ID is empty or already registered, or if the builder is nil.
+// It is intended to be called at package initialization time.
+func RegisterClass(id string, builder ClassBuilder) {
+	if id == "" {
+		panic("wrappable-errors: empty class ID")
+	}
+
+	if builder == nil {
+		panic(fmt.Sprintf("wrappable-errors: class %q must have a builder", id))
+	}
+
+	typ := reflect.TypeOf(builder(New(id)))
+
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, exists := registry.classes[id]; exists {
+		panic(fmt.Sprintf("wrappable-errors: class %q is already registered", id))
+	}
+
+	registry.classes[id] = builder
+	registry.classIDs[typ] = id
+}
+
+// Lookup a registered sentinel error by its ID
+func Lookup(id string) (error, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	sentinel, ok := registry.sentinels[id]
+
+	return sentinel, ok
+}
+
+// ID returns the ID under which an error has been registered as a sentinel.
+//
+// Errors derived from a registered sentinel (e.g. sentinel.Wrap(err)) yield the ID of the sentinel
+// through their topmost error.
+func ID(err error) (string, bool) {
+	if err == nil {
+		return "", false
+	}
+
+	if id, ok := sentinelID(err); ok {
+		return id, true
+	}
+
+	if errable, ok := err.(interface{ Err() error }); ok {
+		if head := errable.Err(); head != nil {
+			return sentinelID(head)
+		}
+	}
+
+	return "", false
+}
+
+func sentinelID(err error) (string, bool) {
+	if !reflect.ValueOf(err).Comparable() {
+		return "", false
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	id, ok := registry.sentinelIDs[err]
+
+	return id, ok
+}
+
+func classID(err error) (string, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	id, ok := registry.classIDs[reflect.TypeOf(err)]
+
+	return id, ok
+}
+
+func lookupClass(id string) (ClassBuilder, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	builder, ok := registry.classes[id]
+
+	return builder, ok
+}
+
+// sentinelHead yields the topmost error of a sentinel, when it does not wrap any other error.
+func sentinelHead(sentinel error) error {
+	wrapper, ok := sentinel.(headTail)
+	if !ok {
+		return sentinel
+	}
+
+	head := wrapper.Err()
+	if head == nil || !sameError(head, wrapper.Unwrap()) {
+		return sentinel
+	}
+
+	return head
+}
+
+// ownedHead yields the topmost error of a sentinel, when this error has been built by this package
+// (e.g. with New(), NewOf() or NewCoded()) and the sentinel does not wrap any other error.
+//
+// Errors derived from the sentinel retain this topmost error, which may then stand for the sentinel.
+// The topmost error of a sentinel built from another error (e.g. NewErr(io.EOF)) is never yielded:
+// it does not belong to the sentinel.
+func ownedHead(sentinel error) (error, bool) {
+	head := sentinelHead(sentinel)
+	if head == sentinel {
+		return nil, false
+	}
+
+	if _, isOwned := head.(owned); !isOwned {
+		return nil, false
+	}
+
+	return head, true
+}
+
+// owned is implemented by the topmost errors built by this package
+type owned interface {
+	error
+
+	ownedByPackage()
+}
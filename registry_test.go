@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/fredbi/wrappable-errors/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type registryTestClass struct {
+	Wrappable
+
+	Resource string `json:"resource"`
+}
+
+var (
+	errRegistryTest1 = New("registry test 1")
+	errRegistryTest2 = NewErr(io.ErrNoProgress)
+	errRegistryTest3 = &registryTestClass{Wrappable: New("registry test 3")}
+)
+
+func init() {
+	Register("test.registry1", errRegistryTest1)
+	Register("test.registry2", errRegistryTest2)
+	Register("test.registry3", errRegistryTest3)
+	Register("test.shortwrite", io.ErrShortWrite)
+
+	RegisterClass("test.class", func(inner Wrappable) error {
+		return &registryTestClass{Wrappable: inner}
+	})
+}
+
+func TestRegistrySentinels(t *testing.T) {
+	t.Parallel()
+
+	e := errRegistryTest1.Wrap(errRegistryTest2).Wrap(io.ErrShortWrite).Errorf("message: %w", io.ErrUnexpectedEOF)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var doc jsonError
+	require.NoError(t, json.Unmarshal(buf, &doc))
+	assert.Equal(t, "test.registry1", doc.ID)
+	require.NotNil(t, doc.Cause)
+	assert.Equal(t, "test.registry2", doc.Cause.ID)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.True(t, Is(decoded, errRegistryTest1))
+	assert.True(t, Is(decoded, errRegistryTest2))
+	assert.True(t, Is(decoded, io.ErrNoProgress))
+	assert.True(t, Is(decoded, io.ErrShortWrite))
+	assert.False(t, Is(decoded, io.ErrUnexpectedEOF)) // not registered
+	assert.ErrorIs(t, Unwrap(decoded), errRegistryTest2)
+
+	sentinel, ok := Lookup("test.registry1")
+	require.True(t, ok)
+	assert.Equal(t, errRegistryTest1, sentinel)
+
+	_, ok = Lookup("test.unknown")
+	assert.False(t, ok)
+}
+
+func TestRegistryClass(t *testing.T) {
+	t.Parallel()
+
+	custom := &registryTestClass{Wrappable: New("not found").Wrap(io.EOF), Resource: "user"}
+	e := New(str).Wrap(custom)
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, "EOF", Root(decoded).Error())
+
+	var target *registryTestClass
+	require.True(t, As(decoded, &target))
+	assert.Equal(t, "user", target.Resource)
+	assert.Equal(t, "not found: EOF", target.Error())
+	assert.Equal(t, "not found", target.Err().Error())
+
+	// registered sentinels of a registered class
+	wrappedSentinel := &registryTestClass{Wrappable: errRegistryTest3.Wrappable.Wrap(io.EOF)}
+	buf, err = json.Marshal(NewErr(wrappedSentinel))
+	require.NoError(t, err)
+
+	decoded, err = FromJSON(buf)
+	require.NoError(t, err)
+	require.True(t, As(decoded, &target))
+	assert.True(t, Is(target.Wrappable, errRegistryTest3.Wrappable))
+}
+
+func TestRegistryUnknown(t *testing.T) {
+	t.Parallel()
+
+	// IDs which are not registered locally are retained
+	const doc = `{"message":"remote","id":"remote.sentinel","cause":{"message":"remote class","class":"remote.class","type":"*remote.Error","fields":{"code":1}}}`
+
+	decoded, err := FromJSON([]byte(doc))
+	require.NoError(t, err)
+	assert.Equal(t, "remote: remote class", decoded.Error())
+
+	buf, err := json.Marshal(decoded)
+	require.NoError(t, err)
+	assert.JSONEq(t, doc, string(buf))
+}
+
+func TestRegisterPanics(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { Register("", io.EOF) })
+	assert.Panics(t, func() { Register("test.nil", nil) })
+	assert.Panics(t, func() { Register("test.registry1", io.EOF) })
+	assert.Panics(t, func() { RegisterClass("", func(inner Wrappable) error { return inner }) })
+	assert.Panics(t, func() { RegisterClass("test.nil", nil) })
+	assert.Panics(t, func() {
+		RegisterClass("test.class", func(inner Wrappable) error { return inner })
+	})
+}
+
+func TestOwnedHead(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Title    string
+		Sentinel error
+		Owned    bool
+	}{
+		{Title: "New", Sentinel: New("owned"), Owned: true},
+		{Title: "NewCoded", Sentinel: NewCoded(404, "owned"), Owned: true},
+		{Title: "NewKinded", Sentinel: NewKinded("db", "owned"), Owned: true},
+		{Title: "NewStatus", Sentinel: NewStatus(codes.NotFound, "owned"), Owned: true},
+		{Title: "NewErr", Sentinel: NewErr(io.EOF)},
+		{Title: "wrapping sentinel", Sentinel: New("owned").Wrap(io.EOF)},
+		{Title: "foreign error", Sentinel: io.EOF},
+	} {
+		head, ok := ownedHead(tc.Sentinel)
+		assert.Equal(t, tc.Owned, ok, tc.Title)
+		if tc.Owned {
+			assert.Equal(t, tc.Sentinel.(headTail).Err(), head, tc.Title)
+		}
+	}
+}
+
+func TestID(t *testing.T) {
+	id, ok := ID(errRegistryTest1)
+	require.True(t, ok)
+	assert.Equal(t, "test.registry1", id)
+
+	id, ok = ID(errRegistryTest1.Wrap(io.EOF))
+	require.True(t, ok)
+	assert.Equal(t, "test.registry1", id)
+
+	_, ok = ID(New("unregistered"))
+	assert.False(t, ok)
+
+	// the topmost error of a sentinel built from another error does not belong to the sentinel
+	_, ok = ID(io.ErrNoProgress)
+	assert.False(t, ok)
+
+	buf, err := json.Marshal(New("message").Wrap(io.ErrNoProgress))
+	require.NoError(t, err)
+
+	decoded, err := FromJSON(buf)
+	require.NoError(t, err)
+	assert.False(t, Is(decoded, errRegistryTest2))
+
+	buf, err = json.Marshal(New("message").Wrap(errRegistryTest2))
+	require.NoError(t, err)
+
+	decoded, err = FromJSON(buf)
+	require.NoError(t, err)
+	assert.Equal(t, errRegistryTest2, Unwrap(decoded)) // the sentinel itself is restored
+
+	_, ok = ID(nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts made by Retry when the policy does not specify it
+	DefaultMaxAttempts = 3
+
+	// DefaultInitialBackoff is the delay before the first retry when the policy does not specify it
+	DefaultInitialBackoff = 100 * time.Millisecond
+
+	// DefaultMultiplier is the growth factor of the backoff when the policy does not specify it
+	DefaultMultiplier = 2.0
+)
+
+// Clock tells Retry how to wait. It may be replaced by a fake clock in tests.
+type Clock interface {
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// RetryPolicy configures Retry.
+//
+// The zero value retries up to DefaultMaxAttempts times, with an exponential backoff
+// starting at DefaultInitialBackoff and no jitter.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first one.
+	// Zero means DefaultMaxAttempts, and a negative value means no limit.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Zero means DefaultInitialBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// Multiplier is the growth factor of the delay after each attempt. Values lower than 1 mean DefaultMultiplier.
+	Multiplier float64
+
+	// Jitter is the fraction of the delay which is randomly removed, between 0 and 1.
+	Jitter float64
+
+	// RetryUnclassified retries errors which are neither retryable nor permanent (see IsRetryable and IsPermanent).
+	// By default, only retryable errors are retried.
+	RetryUnclassified bool
+
+	// Clock used to wait between attempts. Defaults to the system clock.
+	Clock Clock
+
+	// Rand yields random numbers in [0, 1) to apply jitter. Defaults to math/rand/v2.Float64.
+	Rand func() float64
+}
+
+// Retry calls fn until it succeeds, fails with an error which is not retryable, the maximum number
+// of attempts is reached or the context is done.
+//
+// Delays between attempts grow exponentially, with some jitter. A retry-after hint carried by the
+// error (see MarkRetryableAfter) is honoured when it is longer than the computed delay.
+//
+// The returned error joins the errors of all attempts (see Join), and the error of the context if it is done,
+// so errors.Is matches any of them. It is nil if the last attempt succeeds.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(context.Context) error) Wrappable {
+	policy = policy.withDefaults()
+
+	var attempts []error
+
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return Join(append(attempts, err)...)
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		attempts = append(attempts, err)
+
+		if !policy.shouldRetry(err) || (policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts) {
+			return Join(attempts...)
+		}
+
+		select {
+		case <-ctx.Done():
+			return Join(append(attempts, ctx.Err())...)
+		case <-policy.Clock.After(policy.delay(attempt, err)):
+		}
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultInitialBackoff
+	}
+
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultMultiplier
+	}
+
+	p.Jitter = min(max(p.Jitter, 0), 1)
+
+	if p.Clock == nil {
+		p.Clock = systemClock{}
+	}
+
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+
+	return p
+}
+
+func (p RetryPolicy) shouldRetry(err error) bool {
+	retryable, decided := classifyRetry(err)
+	if decided {
+		return retryable
+	}
+
+	return p.RetryUnclassified
+}
+
+// delay before the next attempt, after the given number of failed attempts
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		backoff = min(backoff, float64(p.MaxBackoff))
+	}
+	backoff = min(backoff, float64(math.MaxInt64/2)) // avoid overflowing time.Duration
+
+	d := time.Duration(backoff * (1 - p.Jitter*p.Rand()))
+
+	if after, ok := RetryAfter(err); ok && after > d {
+		return after
+	}
+
+	return d
+}
@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock records the delays and does not wait
+type fakeClock struct {
+	delays []time.Duration
+	onWait func()
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	if c.onWait != nil {
+		c.onWait()
+	}
+
+	ch := make(chan time.Time, 1)
+	ch <- time.Time{}
+
+	return ch
+}
+
+// failing returns a function which fails with the given errors, then succeeds
+func failing(calls *int, errs ...error) func(context.Context) error {
+	return func(context.Context) error {
+		*calls++
+		if *calls > len(errs) {
+			return nil
+		}
+
+		return errs[*calls-1]
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	errRetryable1 := MarkRetryable(New("retryable 1"))
+	errRetryable2 := MarkRetryable(New("retryable 2"))
+	errPermanent := MarkPermanent(New("permanent"))
+
+	t.Run("should succeed at once", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		require.NoError(t, Retry(ctx, RetryPolicy{Clock: clock}, failing(&calls)))
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, clock.delays)
+	})
+
+	t.Run("should succeed after retries with exponential backoff", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, Multiplier: 3, Clock: clock}
+		require.NoError(t, Retry(ctx, policy, failing(&calls, errRetryable1, errRetryable2, errRetryable1)))
+		assert.Equal(t, 4, calls)
+		assert.Equal(t, []time.Duration{time.Second, 3 * time.Second, 9 * time.Second}, clock.delays)
+	})
+
+	t.Run("should cap the backoff and apply jitter", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		policy := RetryPolicy{
+			MaxAttempts:    4,
+			InitialBackoff: time.Second,
+			MaxBackoff:     3 * time.Second,
+			Jitter:         0.5,
+			Rand:           func() float64 { return 0.5 },
+			Clock:          clock,
+		}
+		require.NoError(t, Retry(ctx, policy, failing(&calls, errRetryable1, errRetryable1, errRetryable1)))
+		assert.Equal(t, []time.Duration{750 * time.Millisecond, 1500 * time.Millisecond, 2250 * time.Millisecond}, clock.delays)
+	})
+
+	t.Run("should give up after max attempts, with all errors", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		err := Retry(ctx, RetryPolicy{Clock: clock}, failing(&calls, errRetryable1, errRetryable2, errRetryable1, errRetryable2))
+		require.Error(t, err)
+		assert.Equal(t, DefaultMaxAttempts, calls)
+		assert.Equal(t, []time.Duration{DefaultInitialBackoff, 2 * DefaultInitialBackoff}, clock.delays)
+		assert.ErrorIs(t, err, errRetryable1)
+		assert.ErrorIs(t, err, errRetryable2)
+		assert.Len(t, RootCauses(err), DefaultMaxAttempts)
+	})
+
+	t.Run("should stop on permanent errors", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		err := Retry(ctx, RetryPolicy{Clock: clock}, failing(&calls, errRetryable1, errPermanent, errRetryable1))
+		require.Error(t, err)
+		assert.Equal(t, 2, calls)
+		assert.ErrorIs(t, err, errRetryable1)
+		assert.ErrorIs(t, err, errPermanent)
+	})
+
+	t.Run("should stop on unclassified errors", func(t *testing.T) {
+		var calls int
+
+		err := Retry(ctx, RetryPolicy{Clock: &fakeClock{}}, failing(&calls, io.EOF, io.EOF))
+		require.ErrorIs(t, err, io.EOF)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should retry unclassified errors on demand", func(t *testing.T) {
+		var calls int
+
+		policy := RetryPolicy{RetryUnclassified: true, Clock: &fakeClock{}}
+		require.NoError(t, Retry(ctx, policy, failing(&calls, io.EOF, io.EOF)))
+		assert.Equal(t, 3, calls)
+
+		calls = 0
+		err := Retry(ctx, policy, failing(&calls, io.EOF, errPermanent))
+		require.ErrorIs(t, err, errPermanent)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("should honour retry-after hints", func(t *testing.T) {
+		clock := &fakeClock{}
+		var calls int
+
+		policy := RetryPolicy{InitialBackoff: time.Second, Clock: clock}
+		throttled := MarkRetryableAfter(New("throttled"), 5*time.Second)
+		soon := MarkRetryableAfter(New("soon"), time.Millisecond)
+		require.NoError(t, Retry(ctx, policy, failing(&calls, throttled, soon)))
+		assert.Equal(t, []time.Duration{5 * time.Second, 2 * time.Second}, clock.delays)
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		clock := &fakeClock{onWait: cancel}
+		var calls int
+
+		err := Retry(ctx, RetryPolicy{MaxAttempts: -1, Clock: clock}, failing(&calls, errRetryable1, errRetryable2))
+		require.Error(t, err)
+		assert.Equal(t, 1, calls)
+		assert.ErrorIs(t, err, errRetryable1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should not call when the context is already done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var calls int
+
+		err := Retry(ctx, RetryPolicy{}, failing(&calls))
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, calls)
+	})
+
+	t.Run("should wait with the system clock", func(t *testing.T) {
+		var calls int
+
+		policy := RetryPolicy{InitialBackoff: time.Millisecond}
+		require.NoError(t, Retry(ctx, policy, failing(&calls, errRetryable1)))
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestIsPermanent(t *testing.T) {
+	assert.True(t, IsPermanent(MarkPermanent(io.EOF)))
+	assert.False(t, IsPermanent(MarkRetryable(io.EOF)))
+	assert.False(t, IsPermanent(io.EOF))
+	assert.True(t, IsPermanent(New("call").Wrap(context.Canceled)))
+}
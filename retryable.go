@@ -0,0 +1,189 @@
+package errors
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/fredbi/wrappable-errors/codes"
+)
+
+const (
+	// RetryableKey is the attribute set by MarkRetryable and MarkPermanent
+	RetryableKey = "retryable"
+
+	// RetryAfterKey is the attribute set by MarkRetryableAfter
+	RetryAfterKey = "retry_after"
+)
+
+// MarkRetryable marks an error as retryable.
+//
+// The mark is an attribute (see With), so it is retained when wrapping other errors, encoded to JSON and logged.
+func MarkRetryable(err error) Wrappable {
+	return mark(err, RetryableKey, true)
+}
+
+// MarkRetryableAfter marks an error as retryable, with a hint about how long to wait before retrying.
+func MarkRetryableAfter(err error, after time.Duration) Wrappable {
+	return mark(err, RetryableKey, true, RetryAfterKey, after)
+}
+
+// MarkPermanent marks an error as not retryable.
+//
+// This overrides any retryable cause wrapped by the error.
+func MarkPermanent(err error) Wrappable {
+	return mark(err, RetryableKey, false)
+}
+
+func mark(err error, keyvals ...interface{}) Wrappable {
+	if err == nil {
+		return nil
+	}
+
+	wrapper, ok := err.(Wrappable)
+	if !ok {
+		wrapper = NewErr(err)
+	}
+
+	return wrapper.With(keyvals...)
+}
+
+// IsRetryable tells if an operation which failed with this error may be retried.
+//
+// The chain is walked like Walk, and the outermost error which decides wins:
+//   - errors marked with MarkRetryable, MarkRetryableAfter or MarkPermanent
+//   - errors with a Timeout() bool method which returns true are retryable, e.g. context.DeadlineExceeded
+//     or a net.Error
+//   - errors with a Temporary() bool method are retryable if it returns true, and permanent otherwise
+//   - errors resolving to a canonical code (see Status): Unavailable, ResourceExhausted, Aborted and DeadlineExceeded
+//     are retryable; OK, Unknown and Internal do not decide; other codes are permanent
+//
+// An error for which no decision is found is not retryable.
+func IsRetryable(err error) bool {
+	retryable, _ := classifyRetry(err)
+
+	return retryable
+}
+
+// IsPermanent tells if an operation which failed with this error must not be retried.
+//
+// Unlike !IsRetryable(err), an error for which no decision is found is not permanent. See IsRetryable.
+func IsPermanent(err error) bool {
+	retryable, decided := classifyRetry(err)
+
+	return decided && !retryable
+}
+
+// classifyRetry returns the outermost retry decision found in a chain of errors
+func classifyRetry(err error) (retryable bool, decided bool) {
+	walk(err, true, func(node error, _ int, _ []int, leaf bool) WalkAction {
+		retryable, decided = retryableOf(node, leaf)
+		if !decided {
+			return WalkContinue
+		}
+
+		return WalkStop
+	})
+
+	return retryable, decided
+}
+
+// RetryAfter returns the outermost hint about how long to wait before retrying, set with MarkRetryableAfter.
+func RetryAfter(err error) (time.Duration, bool) {
+	var (
+		after time.Duration
+		found bool
+	)
+
+	walk(err, true, func(node error, _ int, _ []int, _ bool) WalkAction {
+		value, ok := attrOf(node, RetryAfterKey)
+		if !ok {
+			return WalkContinue
+		}
+
+		after, found = toDuration(value)
+		if found {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return after, found
+}
+
+// retryableOf decides if a single error in a chain is retryable
+func retryableOf(err error, leaf bool) (retryable bool, decided bool) {
+	if value, ok := attrOf(err, RetryableKey); ok {
+		if marked, isBool := value.(bool); isBool {
+			return marked, true
+		}
+	}
+
+	if timeout, ok := err.(interface{ Timeout() bool }); ok && timeout.Timeout() {
+		return true, true
+	}
+
+	if temporary, ok := err.(interface{ Temporary() bool }); ok {
+		return temporary.Temporary(), true
+	}
+
+	code, ok := statusOf(err, leaf)
+	if !ok {
+		return false, false
+	}
+
+	switch code {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+		return true, true
+	case codes.OK, codes.Unknown, codes.Internal:
+		return false, false
+	default:
+		return false, true
+	}
+}
+
+// attrOf returns the value of an attribute carried by a single error in a chain.
+//
+// The last value set takes precedence.
+func attrOf(err error, key string) (interface{}, bool) {
+	attributer, ok := err.(Attributer)
+	if !ok {
+		return nil, false
+	}
+
+	attrs := attributer.Attributes()
+	for i := len(attrs) - 1; i >= 0; i-- {
+		if attrs[i].Key == key {
+			return attrs[i].Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// toDuration converts an attribute value to a duration, including values decoded from JSON
+func toDuration(value interface{}) (time.Duration, bool) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, true
+	case json.Number:
+		n, err := v.Int64()
+		if err != nil {
+			return 0, false
+		}
+
+		return time.Duration(n), true
+	case float64:
+		return time.Duration(v), true
+	case int64:
+		return time.Duration(v), true
+	case int:
+		return time.Duration(v), true
+	case string:
+		d, err := time.ParseDuration(v)
+
+		return d, err == nil
+	default:
+		return 0, false
+	}
+}
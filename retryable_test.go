@@ -0,0 +1,127 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fredbi/wrappable-errors/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type temporaryTestError struct {
+	temporary bool
+}
+
+func (e temporaryTestError) Error() string   { return "temporary test" }
+func (e temporaryTestError) Temporary() bool { return e.temporary }
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Title    string
+		Err      error
+		Expected bool
+	}{
+		{Title: "nil error", Err: nil},
+		{Title: "unmarked error", Err: New("failed").Wrap(io.EOF)},
+		{Title: "marked retryable", Err: MarkRetryable(io.EOF), Expected: true},
+		{Title: "marked wrappable", Err: MarkRetryable(New("failed")).Wrap(io.EOF), Expected: true},
+		{Title: "wrapped marked", Err: New("failed").Wrap(MarkRetryable(io.EOF)), Expected: true},
+		{Title: "marked permanent", Err: MarkPermanent(context.DeadlineExceeded)},
+		{Title: "outer permanent overrides inner retryable", Err: MarkPermanent(New("failed").Wrap(MarkRetryable(io.EOF)))},
+		{Title: "remarked permanent", Err: MarkPermanent(MarkRetryable(io.EOF))},
+		{Title: "outer retryable overrides inner permanent", Err: MarkRetryable(New("failed").Wrap(MarkPermanent(io.EOF))), Expected: true},
+		{Title: "deadline exceeded", Err: fmt.Errorf("call: %w", context.DeadlineExceeded), Expected: true},
+		{Title: "os deadline exceeded", Err: New("read").Wrap(os.ErrDeadlineExceeded), Expected: true},
+		{Title: "canceled", Err: New("call").Wrap(context.Canceled)},
+		{Title: "net timeout", Err: &net.DNSError{Err: "timeout", IsTimeout: true}, Expected: true},
+		{Title: "net temporary", Err: &net.DNSError{Err: "temporary", IsTemporary: true}, Expected: true},
+		{Title: "net permanent", Err: &net.DNSError{Err: "no such host", IsNotFound: true}},
+		{Title: "temporary", Err: New("call").Wrap(temporaryTestError{temporary: true}), Expected: true},
+		{Title: "not temporary", Err: New("call").Wrap(temporaryTestError{temporary: false})},
+		{Title: "unavailable", Err: NewStatus(codes.Unavailable, "down"), Expected: true},
+		{Title: "internal does not decide", Err: NewStatus(codes.Internal, "failed").Wrap(NewStatus(codes.Aborted, "conflict")), Expected: true},
+		{Title: "invalid argument", Err: NewStatus(codes.InvalidArgument, "invalid").Wrap(NewStatus(codes.Unavailable, "down"))},
+	} {
+		assert.Equal(t, tc.Expected, IsRetryable(tc.Err), tc.Title)
+	}
+
+	t.Run("should not mark nil errors", func(t *testing.T) {
+		assert.Nil(t, MarkRetryable(nil))
+		assert.Nil(t, MarkPermanent(nil))
+	})
+
+	t.Run("should retain the error", func(t *testing.T) {
+		err := MarkRetryable(io.EOF)
+		assert.Equal(t, "EOF", err.Error())
+		assert.ErrorIs(t, err, io.EOF)
+	})
+}
+
+func TestIsRetryableReentrant(t *testing.T) {
+	t.Parallel()
+
+	decided := make(chan bool, 1)
+	go func() {
+		decided <- IsRetryable(New("call").Wrap(statusReentrantError{cause: NewStatus(codes.Unavailable, "down")}))
+	}()
+
+	select {
+	case retryable := <-decided:
+		assert.True(t, retryable)
+	case <-time.After(2 * time.Second):
+		t.Fatal("IsRetryable is blocked")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	_, ok := RetryAfter(MarkRetryable(io.EOF))
+	assert.False(t, ok)
+
+	err := New("failed").Wrap(MarkRetryableAfter(io.EOF, time.Second))
+	require.True(t, IsRetryable(err))
+
+	after, ok := RetryAfter(err)
+	require.True(t, ok)
+	assert.Equal(t, time.Second, after)
+
+	t.Run("outermost hint wins", func(t *testing.T) {
+		after, ok := RetryAfter(MarkRetryableAfter(err, time.Minute))
+		require.True(t, ok)
+		assert.Equal(t, time.Minute, after)
+	})
+
+	t.Run("should survive JSON", func(t *testing.T) {
+		data, e := json.Marshal(err)
+		require.NoError(t, e)
+
+		decoded, e := FromJSON(data)
+		require.NoError(t, e)
+		assert.True(t, IsRetryable(decoded))
+
+		after, ok := RetryAfter(decoded)
+		require.True(t, ok)
+		assert.Equal(t, time.Second, after)
+	})
+
+	t.Run("should convert hints", func(t *testing.T) {
+		for _, value := range []interface{}{time.Second, json.Number("1000000000"), float64(time.Second), int64(time.Second), int(time.Second), "1s"} {
+			after, ok := RetryAfter(NewErr(io.EOF).With(RetryAfterKey, value))
+			require.True(t, ok)
+			assert.Equal(t, time.Second, after)
+		}
+
+		_, ok := RetryAfter(NewErr(io.EOF).With(RetryAfterKey, "soon"))
+		assert.False(t, ok)
+	})
+}
@@ -1,38 +1,33 @@
 package errors
 
-import "errors"
+var (
+	_ MultiRootable = &wrapped{}
+	_ MultiRootable = &joined{}
+	_ MultiRootable = &stacked{}
+)
 
-var _ Rootable = &wrapped{}
+// MultiRootable is a Rootable that knows how to yield all the root causes of a tree of errors
+type MultiRootable interface {
+	Rootable
 
-// Root cause of the error: returns the deepest wrapped error in the chain
+	// RootCauses returns all the deepest errors in the tree (leaves, i.e. "root causes")
+	RootCauses() []error
+}
+
+// Root cause of the error: returns the deepest wrapped error in the chain.
+//
+// When the chain branches into a tree of errors (e.g. with errors.Join()), the first branch is followed.
 func Root(err error) error {
 	if rootable, ok := err.(Rootable); ok {
 		return rootable.Root()
 	}
 
-	last := err
-	next := errors.Unwrap(err)
-
-	for next != nil {
-		if rootable, ok := next.(Rootable); ok {
-			return rootable.Root()
-		}
-
-		last = next
-
-		if unwrapped, ok := next.(interface{ Unwrap() error }); ok {
-			next = unwrapped.Unwrap()
-		} else {
-			next = nil
-		}
-	}
-
-	return last
+	return rootOf(err)
 }
 
 // NewWithRoot wrappable & rootable error from a string
 func NewWithRoot(msg string) Rootable {
-	return &wrapped{err: errors.New(msg)}
+	return &wrapped{err: newString(msg)}
 }
 
 // NewErrWithRoot wrappable & rootable error from another error
@@ -42,22 +37,97 @@ func NewErrWithRoot(err error) Rootable {
 
 // Root returns the root cause of a wrapped error
 func (e wrapped) Root() error {
-	last := e.err
-	next := e.Unwrap()
-
-	for next != nil {
-		if errable, ok := next.(interface{ Err() error }); ok {
-			last = errable.Err()
-		} else {
-			last = next
+	return rootOf(e)
+}
+
+// RootCauses of the error: returns all the deepest wrapped errors in the tree of errors.
+//
+// Leaves are returned in a deterministic order, exploring the tree depth-first, then from the first
+// to the last branch. For a linear chain of errors, this returns the same error as Root().
+//
+// Errors implementing MultiRootable contribute their own root causes. Errors which are only Rootable
+// contribute their Root().
+func RootCauses(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	return appendRootCauses(nil, err)
+}
+
+// RootCauses returns the root causes of a wrapped error
+func (e wrapped) RootCauses() []error {
+	if e.cause != nil {
+		return appendRootCauses(nil, e.cause)
+	}
+
+	return appendRootCauses(nil, e.err)
+}
+
+// Root returns the root cause of the first joined error
+func (e *joined) Root() error {
+	return Root(e.errs[0])
+}
+
+// RootCauses returns the root causes of all joined errors
+func (e *joined) RootCauses() []error {
+	var roots []error
+	for _, err := range e.errs {
+		roots = appendRootCauses(roots, err)
+	}
+
+	return roots
+}
+
+// RootCauses returns the root causes of the underlying error
+func (s *stacked) RootCauses() []error {
+	return RootCauses(s.Wrappable)
+}
+
+// rootOf follows the first branch of a tree of errors down to its deepest error.
+//
+// Nested errors which are Rootable yield their own root.
+func rootOf(err error) error {
+	root := err
+
+	walk(err, false, func(node error, depth int, _ []int, leaf bool) WalkAction {
+		if rootable, ok := node.(Rootable); ok && depth > 0 {
+			root = rootable.Root()
+
+			return WalkStop
 		}
 
-		if unwrapped, ok := next.(interface{ Unwrap() error }); ok {
-			next = unwrapped.Unwrap()
-		} else {
-			next = nil
+		if leaf {
+			root = node
+
+			return WalkStop
 		}
-	}
 
-	return last
+		return WalkContinue
+	})
+
+	return root
+}
+
+func appendRootCauses(roots []error, err error) []error {
+	walk(err, false, func(node error, _ int, _ []int, leaf bool) WalkAction {
+		switch rootable := node.(type) {
+		case MultiRootable:
+			roots = append(roots, rootable.RootCauses()...)
+
+			return WalkSkip
+		case Rootable:
+			roots = append(roots, rootable.Root())
+
+			return WalkSkip
+		}
+
+		if leaf {
+			roots = append(roots, node)
+		}
+
+		return WalkContinue
+	})
+
+	return roots
 }
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"io"
 	"testing"
@@ -28,3 +29,61 @@ func TestRooted(t *testing.T) {
 	assert.ErrorIs(t, Root(e4), io.ErrClosedPipe)
 	assert.ErrorIs(t, Root(e5), io.ErrClosedPipe)
 }
+
+func TestRootedTree(t *testing.T) {
+	joined := stderrors.Join(nil, fmt.Errorf("branch: %w", io.ErrClosedPipe), io.EOF)
+
+	assert.ErrorIs(t, Root(joined), io.ErrClosedPipe)
+	assert.ErrorIs(t, Root(fmt.Errorf("wrapped: %w", joined)), io.ErrClosedPipe)
+
+	e1 := NewErrWithRoot(io.ErrUnexpectedEOF).Wrap(joined)
+	assert.ErrorIs(t, Root(e1), io.ErrClosedPipe)
+
+	e2 := NewWithRoot(str).Wrap(fmt.Errorf("multiple: %w, %w", io.ErrShortBuffer, io.EOF))
+	assert.ErrorIs(t, e2.(Rootable).Root(), io.ErrShortBuffer)
+}
+
+type multiRootTest struct {
+	Wrappable
+
+	roots []error
+}
+
+func (e multiRootTest) Root() error {
+	return e.roots[0]
+}
+
+func (e multiRootTest) RootCauses() []error {
+	return e.roots
+}
+
+func TestRootCauses(t *testing.T) {
+	assert.Nil(t, RootCauses(nil))
+	assert.Equal(t, []error{io.EOF}, RootCauses(io.EOF))
+
+	// linear chains
+	e1 := New(str).Wrap(io.ErrClosedPipe).Wrap(io.EOF)
+	assert.Equal(t, []error{io.EOF}, RootCauses(e1))
+	assert.Equal(t, []error{Root(e1)}, RootCauses(e1))
+	assert.Equal(t, []error{io.EOF}, RootCauses(NewErr(io.EOF)))
+	assert.Equal(t, []error{io.ErrClosedPipe}, RootCauses(fmt.Errorf("err: %w", io.ErrClosedPipe)))
+
+	// trees
+	tree := stderrors.Join(
+		fmt.Errorf("branch: %w", io.ErrClosedPipe),
+		nil,
+		Join(io.EOF, New("inner").Wrap(io.ErrUnexpectedEOF)),
+		fmt.Errorf("multiple: %w, %w", io.ErrShortBuffer, io.ErrShortWrite),
+	)
+	expected := []error{io.ErrClosedPipe, io.EOF, io.ErrUnexpectedEOF, io.ErrShortBuffer, io.ErrShortWrite}
+	assert.Equal(t, expected, RootCauses(tree))
+	assert.Equal(t, expected, RootCauses(New(str).Wrap(tree)))
+	assert.Equal(t, expected, RootCauses(WithStack(NewErr(tree))))
+	assert.Equal(t, expected[1:3], New(str).Wrap(Join(io.EOF, New("inner").Wrap(io.ErrUnexpectedEOF))).(MultiRootable).RootCauses())
+
+	assert.ErrorIs(t, Root(Join(io.ErrUnexpectedEOF, io.EOF)), io.ErrUnexpectedEOF)
+
+	// custom root causes
+	custom := multiRootTest{Wrappable: New("custom"), roots: []error{io.ErrNoProgress, io.ErrShortWrite}}
+	assert.Equal(t, []error{io.EOF, io.ErrNoProgress, io.ErrShortWrite}, RootCauses(Join(io.EOF, custom)))
+}
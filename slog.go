@@ -0,0 +1,153 @@
+package errors
+
+import (
+	"log/slog"
+	"strings"
+)
+
+var (
+	_ slog.LogValuer = wrapped{}
+	_ slog.LogValuer = &chain{}
+	_ slog.LogValuer = &joined{}
+	_ slog.LogValuer = &stacked{}
+)
+
+// LogValue renders an error as a structured value for log/slog.
+//
+// The value is a group with the following keys:
+//   - "message": the error message, as with Error()
+//   - "chain": the messages of all the errors in the chain, from head to tail (only when there is more than one)
+//   - "attributes": a group with the attributes collected with Attributes() (if any)
+//   - "stack": the stack trace captured with WithStack() (if any)
+func LogValue(err error) slog.Value {
+	if err == nil {
+		return slog.Value{}
+	}
+
+	attrs := make([]slog.Attr, 0, 4)
+	attrs = append(attrs, slog.String("message", err.Error()))
+
+	if layers := messages(err); len(layers) > 1 {
+		attrs = append(attrs, slog.Any("chain", layers))
+	}
+
+	if collected := Attributes(err); len(collected) > 0 {
+		group := make([]slog.Attr, 0, len(collected))
+		for _, attr := range collected {
+			group = append(group, slog.Any(attr.Key, attr.Value))
+		}
+		attrs = append(attrs, slog.Attr{Key: "attributes", Value: slog.GroupValue(group...)})
+	}
+
+	if traceable, ok := AsType[Traceable](err); ok {
+		trace := traceable.StackTrace()
+		frames := make([]string, 0, len(trace))
+		for _, frame := range trace {
+			frames = append(frames, frame.String())
+		}
+		attrs = append(attrs, slog.Any("stack", frames))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer
+func (e wrapped) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer
+func (c *chain) LogValue() slog.Value {
+	return LogValue(c)
+}
+
+// LogValue implements slog.LogValuer
+func (e *joined) LogValue() slog.Value {
+	return LogValue(e)
+}
+
+// LogValue implements slog.LogValuer
+func (s *stacked) LogValue() slog.Value {
+	return LogValue(s)
+}
+
+// LogValue implements slog.LogValuer
+func (c Class[T]) LogValue() slog.Value {
+	return LogValue(c)
+}
+
+// messages collects the messages of the errors in a chain, from head to tail.
+//
+// Errors which are not built by this package are reported with their full message, since their
+// message usually includes the message of the errors they wrap. When they wrap errors from this package
+// (e.g. with fmt.Errorf("context: %w", err)), the walk goes on below them, and they are reported with
+// their own message only.
+func messages(err error) []string {
+	var layers []string
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		if isOwn(node) {
+			return WalkContinue
+		}
+
+		if !wrapsOwn(node) {
+			layers = append(layers, node.Error())
+
+			return WalkSkip
+		}
+
+		layers = append(layers, ownMessage(node))
+
+		return WalkContinue
+	})
+
+	return layers
+}
+
+func isOwn(err error) bool {
+	switch err.(type) {
+	case headTail, *joined:
+		return true
+	default:
+		return false
+	}
+}
+
+// wrapsOwn tells if some error built by this package is nested in an error
+func wrapsOwn(err error) bool {
+	var found bool
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		if isOwn(node) {
+			found = true
+
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return found
+}
+
+// ownMessage yields the message of an error without the message of the error it wraps,
+// when the former ends with the latter (e.g. with fmt.Errorf("context: %w", err)).
+func ownMessage(err error) string {
+	msg := err.Error()
+
+	unwrapper, ok := err.(interface{ Unwrap() error })
+	if !ok {
+		return msg
+	}
+
+	inner := unwrapper.Unwrap()
+	if inner == nil {
+		return msg
+	}
+
+	if prefix, found := strings.CutSuffix(msg, inner.Error()); found && prefix != "" {
+		return strings.TrimSuffix(prefix, ": ")
+	}
+
+	return msg
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logJSON(t *testing.T, args ...interface{}) map[string]interface{} {
+	t.Helper()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("failure", args...)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	return record
+}
+
+func TestLogValue(t *testing.T) {
+	t.Parallel()
+
+	e := New(str).With("request_id", "abc").Wrap(io.EOF).Errorf("message: %w", io.ErrUnexpectedEOF)
+
+	record := logJSON(t, "error", e)
+	assert.Equal(t, map[string]interface{}{
+		"message":    e.Error(),
+		"chain":      []interface{}{str, "EOF", "message: unexpected EOF"},
+		"attributes": map[string]interface{}{"request_id": "abc"},
+	}, record["error"])
+
+	// single errors
+	record = logJSON(t, "error", New(str))
+	assert.Equal(t, map[string]interface{}{"message": str}, record["error"])
+
+	record = logJSON(t, "error", NewErr(multiErr{io.EOF}))
+	assert.Equal(t, map[string]interface{}{"message": "multi"}, record["error"])
+
+	assert.True(t, LogValue(nil).Equal(slog.Value{}))
+}
+
+func TestLogValueVariants(t *testing.T) {
+	t.Parallel()
+
+	record := logJSON(t, "error", WithStack(io.EOF))
+	group, ok := record["error"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "EOF", group["message"])
+	stack, ok := group["stack"].([]interface{})
+	require.True(t, ok)
+	require.NotEmpty(t, stack)
+	assert.Contains(t, stack[0], "TestLogValueVariants")
+
+	record = logJSON(t, "error", Join(io.EOF, New(str).Wrap(io.ErrClosedPipe)))
+	assert.Equal(t, []interface{}{"EOF", str, "io: read/write on closed pipe"}, record["error"].(map[string]interface{})["chain"])
+
+	tail := Unwrap(New(str).Wrap(io.EOF).Wrap(io.ErrClosedPipe))
+	record = logJSON(t, "error", tail)
+	assert.Equal(t, []interface{}{"EOF", "io: read/write on closed pipe"}, record["error"].(map[string]interface{})["chain"])
+
+	// errors from this package wrapped by other errors
+	record = logJSON(t, "error", LogValue(fmt.Errorf("outer: %w", New(str).Wrap(fmt.Errorf("inner: %w", io.EOF)))))
+	assert.Equal(t, []interface{}{"outer", str, "inner: EOF"}, record["error"].(map[string]interface{})["chain"])
+}
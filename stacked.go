@@ -1,24 +1,174 @@
 package errors
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+const maxStackDepth = 32
+
 // Traceable knows how return a runtime stack trace captured within an error
 type Traceable interface {
-	StackTrace()
+	Wrappable
+
+	// StackTrace returns the frames of the runtime stack captured when the error was created
+	StackTrace() StackTrace
+}
+
+// Frame describes a single function call in a stack trace
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String representation of a frame, as "function file:line"
+func (f Frame) String() string {
+	return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
 }
 
-// WithStack compose an error with a stack trace
+// StackTrace is a stack of frames, from innermost (newest) to outermost (oldest) call.
+type StackTrace []Frame
+
+var (
+	_ Traceable        = &stacked{}
+	_ Rootable         = &stacked{}
+	_ json.Marshaler   = &stacked{}
+	_ json.Unmarshaler = &stacked{}
+)
+
+// WithStack compose an error with a stack trace captured at the call site.
+//
+// If err is already a Wrappable, the resulting error keeps its stack of errors. Otherwise,
+// err is wrapped as with NewErr().
+//
+// WithStack returns nil if err is nil.
 func WithStack(err error) Traceable {
+	if err == nil {
+		return nil
+	}
+
+	wrapper, ok := err.(Wrappable)
+	if !ok {
+		wrapper = NewErr(err)
+	}
+
 	return &stacked{
-		err:   err,
-		stack: nil,
+		Wrappable: wrapper,
+		stack:     callers(3),
 	}
 }
 
+// stacked is a Wrappable with a runtime stack trace.
+//
+// The stack is captured only once, when the error is created. Wrapped errors
+// share the stack of the original error.
 type stacked struct {
-	err   error
-	stack []string
+	Wrappable
+
+	stack []uintptr
+}
+
+// Wrap another error. Returns a shallow clone which retains the stack trace.
+func (s *stacked) Wrap(err error) Wrappable {
+	return s.wrap(err, 1)
+}
+
+// Errorf wraps a nested error built from the extra message, and retains the stack trace.
+func (s *stacked) Errorf(format string, args ...interface{}) Wrappable {
+	return s.wrap(fmt.Errorf(format, args...), 1)
+}
+
+func (s *stacked) wrap(err error, depth int) Wrappable {
+	if err == nil {
+		return s
+	}
+
+	var wrapper Wrappable
+	if inner, ok := s.Wrappable.(*wrapped); ok {
+		wrapper = inner.wrap(err, depth+1)
+	} else {
+		wrapper = s.Wrappable.Wrap(err)
+	}
+
+	return &stacked{
+		Wrappable: wrapper,
+		stack:     s.stack,
+	}
 }
 
-// StackTrace dumps the stack of an error
-func (s *stacked) StackTrace() {
-	// TODO
+// Is implements errors.Is
+func (s *stacked) Is(err error) bool {
+	if s == err {
+		return true
+	}
+
+	if matcher, ok := err.(*Matcher); ok {
+		return matcher.Match(s)
+	}
+
+	return errors.Is(s.Wrappable, err)
+}
+
+// As implements errors.As
+func (s *stacked) As(target interface{}) bool {
+	return errors.As(s.Wrappable, target)
+}
+
+// Root returns the root cause of the underlying error
+func (s *stacked) Root() error {
+	return Root(s.Wrappable)
+}
+
+// MarshalJSON produces a JSON document describing the stack of errors, with the stack trace. See FromJSON.
+func (s *stacked) MarshalJSON() ([]byte, error) {
+	return json.Marshal(encodeError(s))
+}
+
+// UnmarshalJSON rebuilds the stack of errors with FromJSON. The stack trace is not restored.
+func (s *stacked) UnmarshalJSON(data []byte) error {
+	decoded, err := FromJSON(data)
+	if err != nil {
+		return err
+	}
+
+	s.Wrappable = decoded
+	s.stack = nil
+
+	return nil
+}
+
+// StackTrace resolves the captured program counters into frames
+func (s *stacked) StackTrace() StackTrace {
+	if len(s.stack) == 0 {
+		return nil
+	}
+
+	trace := make(StackTrace, 0, len(s.stack))
+	frames := runtime.CallersFrames(s.stack)
+
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+
+		if !more {
+			break
+		}
+	}
+
+	return trace
+}
+
+// callers captures the program counters of the stack, skipping the innermost frames
+func callers(skip int) []uintptr {
+	var pcs [maxStackDepth]uintptr
+	n := runtime.Callers(skip, pcs[:])
+
+	return pcs[:n:n]
 }
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithStack(t *testing.T) {
+	t.Parallel()
+
+	e := WithStack(io.EOF)
+	assert.Equal(t, io.EOF.Error(), e.Error())
+
+	trace := e.StackTrace()
+	require.NotEmpty(t, trace)
+	assert.True(t, strings.HasSuffix(trace[0].Function, "TestWithStack"))
+	assert.True(t, strings.HasSuffix(trace[0].File, "stacked_test.go"))
+	assert.NotZero(t, trace[0].Line)
+	assert.Contains(t, trace[0].String(), "stacked_test.go:")
+
+	assert.True(t, Is(e, io.EOF))
+	assert.True(t, Is(e, e))
+	assert.ErrorIs(t, Root(e), io.EOF)
+
+	w := e.Wrap(io.ErrClosedPipe).Errorf("message: %w", io.ErrUnexpectedEOF)
+	assert.Equal(t, "EOF: io: read/write on closed pipe: message: unexpected EOF", w.Error())
+	assert.True(t, Is(w, io.EOF))
+	assert.True(t, Is(w, io.ErrClosedPipe))
+	assert.True(t, Is(w, io.ErrUnexpectedEOF))
+	assert.ErrorIs(t, Root(w), io.ErrUnexpectedEOF)
+
+	traceable, ok := w.(Traceable)
+	require.True(t, ok)
+	assert.Equal(t, trace, traceable.StackTrace()) // the stack is retained when wrapping
+
+	var target Traceable
+	require.True(t, As(fmt.Errorf("outer: %w", w), &target))
+	assert.Equal(t, trace, target.StackTrace())
+}
+
+func TestWithStackWrappable(t *testing.T) {
+	t.Parallel()
+
+	sentinel := New(str)
+	e := WithStack(sentinel.Wrap(io.EOF))
+
+	assert.Equal(t, str+": EOF", e.Error())
+	assert.True(t, Is(e, sentinel))
+	assert.True(t, Is(e, io.EOF))
+	assert.Equal(t, e, e.Wrap(nil))
+}
+
+func TestWithStackNil(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, WithStack(nil))
+}
+
+func TestWithStackJSON(t *testing.T) {
+	t.Parallel()
+
+	e := WithStack(New(str).Wrap(io.EOF))
+
+	buf, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	var doc jsonError
+	require.NoError(t, json.Unmarshal(buf, &doc))
+	assert.Equal(t, str, doc.Message)
+	require.NotEmpty(t, doc.Stack)
+	assert.True(t, strings.HasSuffix(doc.Stack[0].Function, "TestWithStackJSON"))
+
+	var decoded stacked
+	require.NoError(t, json.Unmarshal(buf, &decoded))
+	assert.Equal(t, e.Error(), decoded.Error())
+	assert.Equal(t, io.EOF.Error(), Root(&decoded).Error())
+	assert.Empty(t, decoded.StackTrace())
+
+	require.Error(t, json.Unmarshal([]byte(`{"message":`), &decoded))
+}
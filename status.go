@@ -0,0 +1,178 @@
+package errors
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/fredbi/wrappable-errors/codes"
+)
+
+// CanonicalCoded is an error which carries a canonical code
+type CanonicalCoded interface {
+	error
+
+	CanonicalCode() codes.Code
+}
+
+var _ CanonicalCoded = &statusError{}
+
+// canonical maps sentinel errors to canonical codes
+var canonical = struct {
+	sync.RWMutex
+
+	sentinels []error
+	codes     map[error]codes.Code
+}{
+	codes: make(map[error]codes.Code),
+}
+
+func init() {
+	RegisterClass("wrappable-errors.status", func(inner Wrappable) error {
+		return &statusError{Message: inner.Error()}
+	})
+
+	RegisterStatus(context.Canceled, codes.Canceled)
+	RegisterStatus(context.DeadlineExceeded, codes.DeadlineExceeded)
+	RegisterStatus(os.ErrDeadlineExceeded, codes.DeadlineExceeded)
+	RegisterStatus(fs.ErrNotExist, codes.NotFound)
+	RegisterStatus(fs.ErrExist, codes.AlreadyExists)
+	RegisterStatus(fs.ErrPermission, codes.PermissionDenied)
+	RegisterStatus(fs.ErrInvalid, codes.InvalidArgument)
+}
+
+// NewStatus builds a wrappable error from a string, with a canonical code.
+//
+// The code is retained when wrapping other errors.
+func NewStatus(code codes.Code, msg string) Wrappable {
+	return &wrapped{err: &statusError{Value: code, Message: msg}}
+}
+
+// RegisterStatus maps a sentinel error to a canonical code.
+//
+// Errors from the standard library are mapped by default: context.Canceled, context.DeadlineExceeded,
+// os.ErrDeadlineExceeded, and the fs.ErrNotExist, fs.ErrExist, fs.ErrPermission and fs.ErrInvalid errors.
+//
+// RegisterStatus panics if the sentinel is nil or not comparable.
+func RegisterStatus(sentinel error, code codes.Code) {
+	if sentinel == nil || !reflect.ValueOf(sentinel).Comparable() {
+		panic("wrappable-errors: status sentinel must be a non-nil comparable error")
+	}
+
+	canonical.Lock()
+	defer canonical.Unlock()
+
+	if _, exists := canonical.codes[sentinel]; !exists {
+		canonical.sentinels = append(canonical.sentinels, sentinel)
+	}
+	canonical.codes[sentinel] = code
+
+	if head, ok := ownedHead(sentinel); ok {
+		canonical.codes[head] = code
+	}
+}
+
+// Status returns the canonical code of an error.
+//
+// The chain is walked like Walk, and the outermost error which resolves to a code wins.
+// Errors resolve to a code either because they are CanonicalCoded (e.g. built with NewStatus()), or because
+// they match a sentinel registered with RegisterStatus().
+//
+// Like with gRPC, a nil error yields codes.OK and an error which does not resolve to any code yields codes.Unknown.
+func Status(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+
+	if code, ok := lookupStatus(err); ok {
+		return code
+	}
+
+	return codes.Unknown
+}
+
+func lookupStatus(err error) (codes.Code, bool) {
+	var (
+		code  codes.Code
+		found bool
+	)
+
+	walk(err, true, func(node error, _ int, _ []int, leaf bool) WalkAction {
+		code, found = statusOf(node, leaf)
+		if found {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return code, found
+}
+
+// statusOf resolves the canonical code of a single error in a chain.
+//
+// The registry is locked only to read from it: methods of the error may resolve codes themselves.
+func statusOf(err error, leaf bool) (codes.Code, bool) {
+	if coded, ok := err.(CanonicalCoded); ok {
+		return coded.CanonicalCode(), true
+	}
+
+	if code, ok := registeredStatus(err); ok {
+		return code, true
+	}
+
+	// errors such as syscall.Errno match sentinels without being equal to them.
+	// Only leaves are probed, since the Is() method of wrapping errors would match anywhere down the chain.
+	matcher, ok := err.(interface{ Is(error) bool })
+	if !ok || !leaf {
+		return codes.Unknown, false
+	}
+
+	canonical.RLock()
+	sentinels := canonical.sentinels // registered sentinels are only appended
+	canonical.RUnlock()
+
+	for _, sentinel := range sentinels {
+		if matcher.Is(sentinel) {
+			return registeredStatus(sentinel)
+		}
+	}
+
+	return codes.Unknown, false
+}
+
+// registeredStatus yields the code a sentinel has been registered with
+func registeredStatus(err error) (codes.Code, bool) {
+	if !reflect.ValueOf(err).Comparable() {
+		return codes.Unknown, false
+	}
+
+	canonical.RLock()
+	defer canonical.RUnlock()
+
+	code, ok := canonical.codes[err]
+
+	return code, ok
+}
+
+// statusError is an error with a canonical code
+type statusError struct {
+	Value   codes.Code `json:"status"`
+	Message string     `json:"-"`
+}
+
+func (e *statusError) Error() string {
+	return e.Message
+}
+
+func (e *statusError) ownedByPackage() {}
+
+func (e *statusError) CanonicalCode() codes.Code {
+	return e.Value
+}
+
+func (e *statusError) HTTPStatus() int {
+	return e.Value.HTTPStatus()
+}
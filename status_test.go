@@ -0,0 +1,125 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fredbi/wrappable-errors/codes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type statusTestError struct{}
+
+func (statusTestError) Error() string { return "custom" }
+
+func (statusTestError) CanonicalCode() codes.Code { return codes.Aborted }
+
+// statusReentrantError resolves its code from its cause, while a registration is pending
+type statusReentrantError struct {
+	cause error
+}
+
+func (e statusReentrantError) Error() string { return "reentrant" }
+
+func (e statusReentrantError) CanonicalCode() codes.Code {
+	registered := make(chan struct{})
+	go func() {
+		RegisterStatus(New("pending"), codes.Internal)
+		close(registered)
+	}()
+	time.Sleep(10 * time.Millisecond) // the registration waits for the lock, if it is held
+
+	code := Status(e.cause)
+	<-registered
+
+	return code
+}
+
+func TestStatus(t *testing.T) {
+	t.Parallel()
+
+	_, errNotExist := os.Open(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, errNotExist)
+
+	errUnavailable := New("unavailable")
+	RegisterStatus(errUnavailable, codes.Unavailable)
+	errTooShort := NewErr(io.ErrShortBuffer)
+	RegisterStatus(errTooShort, codes.OutOfRange)
+
+	for _, tc := range []struct {
+		Title    string
+		Err      error
+		Expected codes.Code
+	}{
+		{Title: "nil error", Err: nil, Expected: codes.OK},
+		{Title: "unmapped error", Err: io.EOF, Expected: codes.Unknown},
+		{Title: "status error", Err: NewStatus(codes.NotFound, "no such user"), Expected: codes.NotFound},
+		{Title: "wrapping status error", Err: NewStatus(codes.NotFound, "no such user").Wrap(io.EOF), Expected: codes.NotFound},
+		{Title: "wrapped status error", Err: New("get").Wrap(NewStatus(codes.NotFound, "no such user")), Expected: codes.NotFound},
+		{Title: "outermost wins", Err: NewStatus(codes.Internal, "failed").Wrap(context.Canceled), Expected: codes.Internal},
+		{Title: "declared code", Err: fmt.Errorf("wrapped: %w", statusTestError{}), Expected: codes.Aborted},
+		{Title: "registered sentinel", Err: errUnavailable.Wrap(io.EOF), Expected: codes.Unavailable},
+		{Title: "sentinel built from another error", Err: New("read").Wrap(errTooShort), Expected: codes.OutOfRange},
+		{Title: "error wrapped by a sentinel", Err: fmt.Errorf("read: %w", io.ErrShortBuffer), Expected: codes.Unknown},
+		{Title: "context canceled", Err: fmt.Errorf("call: %w", context.Canceled), Expected: codes.Canceled},
+		{Title: "context deadline", Err: New("call").Wrap(context.DeadlineExceeded), Expected: codes.DeadlineExceeded},
+		{Title: "file not found", Err: errNotExist, Expected: codes.NotFound},
+		{Title: "wrapped file not found", Err: New("config").Wrap(errNotExist), Expected: codes.NotFound},
+	} {
+		assert.Equal(t, tc.Expected, Status(tc.Err), tc.Title)
+	}
+
+	t.Run("should panic on invalid sentinel", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterStatus(nil, codes.Internal) })
+	})
+}
+
+func TestStatusReentrant(t *testing.T) {
+	t.Parallel()
+
+	resolved := make(chan codes.Code, 1)
+	go func() {
+		resolved <- Status(New("outer").Wrap(statusReentrantError{cause: context.Canceled}))
+	}()
+
+	select {
+	case code := <-resolved:
+		assert.Equal(t, codes.Canceled, code)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Status is blocked")
+	}
+}
+
+func TestStatusHTTP(t *testing.T) {
+	t.Parallel()
+
+	m := NewHTTPStatusMap(http.StatusInternalServerError)
+	errConflict := New("conflict")
+	m.Register(errConflict, http.StatusConflict)
+
+	assert.Equal(t, http.StatusNotFound, m.Status(NewStatus(codes.NotFound, "no such user")))
+	assert.Equal(t, http.StatusGatewayTimeout, m.Status(New("call").Wrap(context.DeadlineExceeded)))
+	assert.Equal(t, http.StatusConflict, m.Status(NewStatus(codes.NotFound, "no such user").Wrap(errConflict)))
+}
+
+func TestStatusJSON(t *testing.T) {
+	t.Parallel()
+
+	err := NewStatus(codes.PermissionDenied, "denied").Wrap(io.EOF)
+
+	data, e := json.Marshal(err)
+	require.NoError(t, e)
+
+	decoded, e := FromJSON(data)
+	require.NoError(t, e)
+	assert.Equal(t, codes.PermissionDenied, Status(decoded))
+	assert.Equal(t, err.Error(), decoded.Error())
+}
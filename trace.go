@@ -0,0 +1,280 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// MaxReturnTrace is the maximum number of locations retained by a return trace.
+//
+// When the trace grows longer, the oldest locations are elided.
+const MaxReturnTrace = 32
+
+// ReturnTrace is the list of locations an error went through while being returned,
+// from its origin to the latest location.
+type ReturnTrace struct {
+	Frames []Frame `json:"frames"`
+
+	// Elided is the number of locations which have been dropped from the beginning of the trace
+	Elided int `json:"elided,omitempty"`
+}
+
+// Traced is an error which carries a return trace
+type Traced interface {
+	error
+
+	// ReturnTrace returns the locations the error went through while being returned
+	ReturnTrace() (ReturnTrace, bool)
+}
+
+var (
+	_ Traced = &wrapped{}
+	_ Traced = &stacked{}
+)
+
+// WithTrace starts a return trace with the location of the code calling New or NewErr.
+//
+// The locations of the code which then wraps the error, or passes it to Trace, are appended to the trace,
+// which tells how the error propagated. See Trace.
+func WithTrace() Option {
+	return func(e *wrapped) {
+		e.trace = &returnTrace{}
+	}
+}
+
+// Trace appends the location of its caller to the return trace of an error, and returns the error.
+//
+// This is intended to be used when returning errors, e.g. "return errors.Trace(err)", like with the error return traces of Zig.
+// A trace is started if the error does not carry any.
+//
+// Errors from this package retain their type. Other errors are wrapped as with NewErr().
+//
+// Errors which carry a return trace, or wrap an error which carries a trace, also extend it on Wrap() and Errorf().
+// Return traces retain at most MaxReturnTrace locations.
+func Trace(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return traceWith(err, callerPC(1))
+}
+
+// ReturnTraceOf returns the return trace of the outermost error in a chain which carries one
+func ReturnTraceOf(err error) (ReturnTrace, bool) {
+	var (
+		trace ReturnTrace
+		found bool
+	)
+
+	Walk(err, func(node error, _ int, _ []int) WalkAction {
+		traced, ok := node.(Traced)
+		if !ok {
+			return WalkContinue
+		}
+
+		trace, found = traced.ReturnTrace()
+		if found {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+
+	return trace, found
+}
+
+// ReturnTrace returns the locations the error went through while being returned
+func (e wrapped) ReturnTrace() (ReturnTrace, bool) {
+	if e.trace == nil {
+		return ReturnTrace{}, false
+	}
+
+	return e.trace.resolve(), true
+}
+
+// ReturnTrace returns the return trace of the underlying error
+func (s *stacked) ReturnTrace() (ReturnTrace, bool) {
+	if traced, ok := s.Wrappable.(Traced); ok {
+		return traced.ReturnTrace()
+	}
+
+	return ReturnTrace{}, false
+}
+
+// ReturnTrace returns the return trace of the underlying error
+func (c Class[T]) ReturnTrace() (ReturnTrace, bool) {
+	if traced, ok := c.Wrappable.(Traced); ok {
+		return traced.ReturnTrace()
+	}
+
+	return ReturnTrace{}, false
+}
+
+// tracer knows how to extend its return trace, retaining its type
+type tracer interface {
+	error
+
+	returnTrace() *returnTrace
+	traceWith(pc uintptr) error
+}
+
+func traceWith(err error, pc uintptr) error {
+	if t, ok := err.(tracer); ok {
+		return t.traceWith(pc)
+	}
+
+	return &wrapped{err: err, trace: (*returnTrace)(nil).append(pc)}
+}
+
+func (e *wrapped) returnTrace() *returnTrace {
+	return e.trace
+}
+
+func (e *wrapped) traceWith(pc uintptr) error {
+	clone := *e
+	clone.trace = e.trace.append(pc)
+
+	return &clone
+}
+
+func (s *stacked) returnTrace() *returnTrace {
+	if t, ok := s.Wrappable.(tracer); ok {
+		return t.returnTrace()
+	}
+
+	return nil
+}
+
+func (s *stacked) traceWith(pc uintptr) error {
+	inner, ok := traceWith(s.Wrappable, pc).(Wrappable)
+	if !ok {
+		return s
+	}
+
+	return &stacked{Wrappable: inner, stack: s.stack}
+}
+
+func (c Class[T]) returnTrace() *returnTrace {
+	if t, ok := c.Wrappable.(tracer); ok {
+		return t.returnTrace()
+	}
+
+	return nil
+}
+
+func (c Class[T]) traceWith(pc uintptr) error {
+	inner, ok := traceWith(c.Wrappable, pc).(Wrappable)
+	if !ok {
+		return c.Wrappable
+	}
+
+	if traced, isError := any(c.clone(inner)).(error); isError {
+		return traced
+	}
+
+	return inner
+}
+
+// propagatedTrace returns the trace to extend when wrapping err: the trace of the wrapped error wins
+// over the trace of the wrapping error, since the wrapped error is the one being propagated.
+//
+// Errors wrapped by other means, e.g. with fmt.Errorf("%w", err), are unwrapped to find their trace.
+func propagatedTrace(wrapper *returnTrace, err error) *returnTrace {
+	for err != nil {
+		if t, ok := err.(tracer); ok {
+			if trace := t.returnTrace(); trace != nil {
+				return trace
+			}
+
+			break
+		}
+
+		unwrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = unwrapper.Unwrap()
+	}
+
+	return wrapper
+}
+
+// returnTrace is an immutable return trace: appending produces a new trace
+type returnTrace struct {
+	frames []Frame   // decoded locations, which come first
+	pcs    []uintptr // captured locations
+	elided int
+}
+
+// append a location, dropping the oldest ones beyond MaxReturnTrace
+func (t *returnTrace) append(pc uintptr) *returnTrace {
+	if t == nil {
+		t = &returnTrace{}
+	}
+
+	next := &returnTrace{
+		frames: t.frames,
+		elided: t.elided,
+	}
+
+	pcs := make([]uintptr, 0, len(t.pcs)+1)
+	pcs = append(pcs, t.pcs...)
+	next.pcs = append(pcs, pc)
+
+	if excess := len(next.frames) + len(next.pcs) - MaxReturnTrace; excess > 0 {
+		next.elided += excess
+
+		drop := min(excess, len(next.frames))
+		next.frames = next.frames[drop:]
+		next.pcs = next.pcs[excess-drop:]
+	}
+
+	return next
+}
+
+func (t *returnTrace) resolve() ReturnTrace {
+	trace := ReturnTrace{
+		Frames: make([]Frame, 0, len(t.frames)+len(t.pcs)),
+		Elided: t.elided,
+	}
+	trace.Frames = append(trace.Frames, t.frames...)
+
+	for _, pc := range t.pcs {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		trace.Frames = append(trace.Frames, Frame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+	}
+
+	return trace
+}
+
+func decodedTrace(trace *ReturnTrace) *returnTrace {
+	if trace == nil {
+		return nil
+	}
+
+	return &returnTrace{
+		frames: trace.Frames,
+		elided: trace.Elided,
+	}
+}
+
+// String representation of a return trace, with one location per line
+func (t ReturnTrace) String() string {
+	var b strings.Builder
+	b.WriteString("return trace:")
+
+	if t.Elided > 0 {
+		fmt.Fprintf(&b, "\n\t... %d elided", t.Elided)
+	}
+
+	for _, frame := range t.Frames {
+		fmt.Fprintf(&b, "\n\t%s", frame)
+	}
+
+	return b.String()
+}
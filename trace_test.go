@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceLines returns the lines of the frames in a return trace
+func traceLines(t *testing.T, err error) []int {
+	t.Helper()
+
+	trace, ok := ReturnTraceOf(err)
+	require.True(t, ok)
+
+	lines := make([]int, 0, len(trace.Frames))
+	for _, frame := range trace.Frames {
+		assert.Equal(t, "trace_test.go", filepath.Base(frame.File))
+		lines = append(lines, frame.Line)
+	}
+
+	return lines
+}
+
+func TestTrace(t *testing.T) {
+	t.Run("should not trace by default", func(t *testing.T) {
+		_, ok := ReturnTraceOf(New("plain").Wrap(io.EOF))
+		assert.False(t, ok)
+		assert.Nil(t, Trace(nil))
+	})
+
+	t.Run("should trace from New", func(t *testing.T) {
+		err, l1 := New("traced", WithTrace()), line()
+		wrapped, l2 := err.Wrap(io.EOF), line()
+		errorf, l3 := wrapped.Errorf("id %d", 42), line()
+		traced, l4 := Trace(errorf), line()
+
+		assert.Equal(t, []int{l1}, traceLines(t, err))
+		assert.Equal(t, []int{l1, l2}, traceLines(t, wrapped))
+		assert.Equal(t, []int{l1, l2, l3}, traceLines(t, errorf))
+		assert.Equal(t, []int{l1, l2, l3, l4}, traceLines(t, traced))
+
+		assert.Equal(t, errorf.Error(), traced.Error())
+		assert.ErrorIs(t, traced, io.EOF)
+		assert.ErrorIs(t, traced, err)
+	})
+
+	t.Run("should retain the type of errors", func(t *testing.T) {
+		assert.IsType(t, &wrapped{}, Trace(New("wrappable")))
+		assert.IsType(t, &wrapped{}, Trace(io.EOF))
+	})
+
+	t.Run("should trace propagated errors", func(t *testing.T) {
+		errSentinel := New("sentinel")
+
+		inner, l1 := Trace(io.EOF), line()
+		outer, l2 := errSentinel.Wrap(inner), line()
+		viaFmt := fmt.Errorf("context: %w", outer)
+		top, l3 := New("top").Wrap(viaFmt), line()
+
+		assert.Equal(t, []int{l1}, traceLines(t, inner))
+		assert.Equal(t, []int{l1, l2}, traceLines(t, outer))
+		assert.Equal(t, []int{l1, l2, l3}, traceLines(t, top))
+
+		assert.ErrorIs(t, inner, io.EOF)
+		assert.ErrorIs(t, top, errSentinel)
+	})
+
+	t.Run("should trace stacked errors and classes", func(t *testing.T) {
+		type classError struct {
+			Class[classError]
+		}
+
+		stacked, l1 := Trace(WithStack(io.EOF)), line()
+		assert.Implements(t, (*Traceable)(nil), stacked)
+		assert.Equal(t, []int{l1}, traceLines(t, stacked))
+
+		errClass := NewOf[classError]("class")
+		traced, l2 := Trace(errClass), line()
+		require.IsType(t, &classError{}, traced)
+		assert.Equal(t, []int{l2}, traceLines(t, traced))
+
+		wrapped, l3 := traced.(*classError).Wrap(io.EOF), line()
+		assert.Equal(t, []int{l2, l3}, traceLines(t, wrapped))
+	})
+
+	t.Run("should cap the trace", func(t *testing.T) {
+		err := New("traced", WithTrace())
+		for range MaxReturnTrace + 10 {
+			err = Trace(err).(Wrappable)
+		}
+
+		trace, ok := ReturnTraceOf(err)
+		require.True(t, ok)
+		assert.Len(t, trace.Frames, MaxReturnTrace)
+		assert.Equal(t, 11, trace.Elided)
+	})
+
+	t.Run("should print the trace", func(t *testing.T) {
+		err, l1 := New("traced", WithTrace()), line()
+		wrapped, l2 := err.Wrap(io.EOF), line()
+
+		printed := fmt.Sprintf("%+v", wrapped)
+		lines := strings.Split(printed, "\n")
+		require.Len(t, lines, 5)
+		assert.Equal(t, "traced", lines[0])
+		assert.Equal(t, "EOF", lines[1])
+		assert.Equal(t, "return trace:", lines[2])
+		assert.True(t, strings.HasSuffix(lines[3], fmt.Sprintf("trace_test.go:%d", l1)), lines[3])
+		assert.True(t, strings.HasSuffix(lines[4], fmt.Sprintf("trace_test.go:%d", l2)), lines[4])
+
+		assert.Equal(t, "traced: EOF", fmt.Sprintf("%v", wrapped))
+		assert.Contains(t, ReturnTrace{Elided: 2}.String(), "... 2 elided")
+	})
+
+	t.Run("should serialize the trace", func(t *testing.T) {
+		err, l1 := Trace(New("traced").Wrap(io.EOF)), line()
+
+		data, e := json.Marshal(err)
+		require.NoError(t, e)
+		assert.Contains(t, string(data), `"trace":{`)
+
+		decoded, e := FromJSON(data)
+		require.NoError(t, e)
+		assert.Equal(t, err.Error(), decoded.Error())
+		assert.Equal(t, []int{l1}, traceLines(t, decoded))
+
+		again, l2 := Trace(decoded), line()
+		assert.Equal(t, []int{l1, l2}, traceLines(t, again))
+
+		data2, e := json.Marshal(again)
+		require.NoError(t, e)
+		redecoded, e := FromJSON(data2)
+		require.NoError(t, e)
+		assert.Equal(t, []int{l1, l2}, traceLines(t, redecoded))
+	})
+}
+
+func BenchmarkTrace(b *testing.B) {
+	err := New("traced", WithTrace())
+	b.ReportAllocs()
+
+	for range b.N {
+		_ = Trace(err)
+	}
+}
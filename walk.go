@@ -0,0 +1,151 @@
+package errors
+
+import (
+	"iter"
+	"slices"
+)
+
+// WalkAction tells Walk how to proceed after visiting an error
+type WalkAction int
+
+const (
+	// WalkContinue proceeds with the nested errors of the current error
+	WalkContinue WalkAction = iota
+
+	// WalkSkip skips the nested errors of the current error, and proceeds with its siblings
+	WalkSkip
+
+	// WalkStop stops the walk
+	WalkStop
+)
+
+// WalkFunc is called by Walk for every error in the tree of errors.
+//
+// The depth of the root error is 0. The path holds the index of the branch taken at every level,
+// from the root error down to the current node. The path must not be retained after the call returns.
+type WalkFunc func(node error, depth int, path []int) WalkAction
+
+// Node describes an error visited in a tree of errors
+type Node struct {
+	Err   error
+	Depth int
+	Path  []int
+}
+
+// Walk visits all the errors in a chain or tree of errors, depth-first.
+//
+// Every error is visited before its nested errors.
+//
+// Nested errors are determined as follows:
+//   - errors with an Err() method (e.g. Wrappable) yield their topmost error first, then the error they Unwrap() to
+//   - errors joined with Join() yield all the joined errors
+//   - errors with an Unwrap() []error method yield all their non-nil branches
+//   - errors with an Unwrap() error method yield their nested error
+func Walk(err error, fn WalkFunc) {
+	walk(err, true, func(node error, depth int, path []int, _ bool) WalkAction {
+		return fn(node, depth, path)
+	})
+}
+
+// Nodes iterates over all the errors in a chain or tree of errors, in the same order as Walk.
+func Nodes(err error) iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		Walk(err, func(node error, depth int, path []int) WalkAction {
+			if !yield(Node{Err: node, Depth: depth, Path: slices.Clone(path)}) {
+				return WalkStop
+			}
+
+			return WalkContinue
+		})
+	}
+}
+
+// walkFunc is the internal version of WalkFunc, which knows about leaves
+type walkFunc func(node error, depth int, path []int, leaf bool) WalkAction
+
+// walk visits a tree of errors. When heads is false, the topmost errors yielded by Err() are not visited:
+// only the tails of the chains are explored.
+func walk(err error, heads bool, fn walkFunc) {
+	if err == nil {
+		return
+	}
+
+	walkNode(err, 0, make([]int, 0, 8), heads, fn)
+}
+
+func walkNode(node error, depth int, path []int, heads bool, fn walkFunc) bool {
+	branches := children(node, heads)
+
+	switch fn(node, depth, path, len(branches) == 0) {
+	case WalkStop:
+		return false
+	case WalkSkip:
+		return true
+	}
+
+	for i, branch := range branches {
+		if !walkNode(branch, depth+1, append(path, i), heads, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// children of an error in the tree of errors
+func children(err error, heads bool) []error {
+	if multi, ok := err.(*joined); ok {
+		return multi.errs
+	}
+
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		branches := make([]error, 0, len(multi.Unwrap()))
+		for _, branch := range multi.Unwrap() {
+			if branch != nil {
+				branches = append(branches, branch)
+			}
+		}
+
+		return branches
+	}
+
+	var single *wrapped
+	switch e := err.(type) {
+	case *wrapped:
+		single = e
+	case wrapped:
+		single = &e
+	}
+
+	if single != nil && single.cause == nil {
+		// a wrapped error without a cause unwraps to its topmost error, which is yielded only once
+		if single.err == nil {
+			return nil
+		}
+
+		return []error{single.err}
+	}
+
+	var tail error
+	if unwrapper, ok := err.(interface{ Unwrap() error }); ok {
+		tail = unwrapper.Unwrap()
+	}
+
+	if heads {
+		if errable, ok := err.(interface{ Err() error }); ok {
+			if head := errable.Err(); head != nil && !sameError(head, err) && !sameError(head, tail) {
+				if tail == nil {
+					return []error{head}
+				}
+
+				return []error{head, tail}
+			}
+		}
+	}
+
+	if tail == nil {
+		return nil
+	}
+
+	return []error{tail}
+}
@@ -0,0 +1,134 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type visited struct {
+	msg   string
+	depth int
+	path  []int
+}
+
+func collect(err error, action func(error) WalkAction) []visited {
+	var nodes []visited
+
+	Walk(err, func(node error, depth int, path []int) WalkAction {
+		nodes = append(nodes, visited{msg: node.Error(), depth: depth, path: append([]int(nil), path...)})
+
+		return action(node)
+	})
+
+	return nodes
+}
+
+func proceed(error) WalkAction { return WalkContinue }
+
+// multiErr is an error which is not comparable
+type multiErr []error
+
+func (e multiErr) Error() string { return "multi" }
+
+func (e multiErr) Unwrap() []error { return e }
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, collect(nil, proceed))
+	assert.Equal(t, []visited{{msg: "EOF", path: nil}}, collect(io.EOF, proceed))
+
+	e := New("outer").Wrap(fmt.Errorf("wrapped: %w", io.EOF))
+	assert.Equal(t, []visited{
+		{msg: "outer: wrapped: EOF", depth: 0, path: nil},
+		{msg: "outer", depth: 1, path: []int{0}},
+		{msg: "wrapped: EOF", depth: 1, path: []int{1}},
+		{msg: "EOF", depth: 2, path: []int{1, 0}},
+	}, collect(e, proceed))
+
+	// a wrapped error without a cause yields its topmost error only once
+	assert.Equal(t, []visited{
+		{msg: "EOF", depth: 0, path: nil},
+		{msg: "EOF", depth: 1, path: []int{0}},
+	}, collect(NewErr(io.EOF), proceed))
+
+	assert.Equal(t, []visited{
+		{msg: "multi", depth: 0, path: nil},
+		{msg: "multi", depth: 1, path: []int{0}},
+		{msg: "EOF", depth: 2, path: []int{0, 0}},
+	}, collect(NewErr(multiErr{io.EOF}), proceed))
+}
+
+func TestWalkTree(t *testing.T) {
+	t.Parallel()
+
+	tree := New("outer").Wrap(stderrors.Join(
+		Join(io.EOF, io.ErrClosedPipe),
+		fmt.Errorf("multiple: %w, %w", io.ErrShortBuffer, io.ErrShortWrite),
+	))
+
+	nodes := collect(tree, proceed)
+	require.Len(t, nodes, 9)
+
+	msgs := make([]string, 0, len(nodes))
+	for _, node := range nodes[5:] {
+		msgs = append(msgs, node.msg)
+	}
+	assert.Equal(t, []string{"io: read/write on closed pipe", "multiple: short buffer, short write", "short buffer", "short write"}, msgs)
+	assert.Equal(t, visited{msg: "short write", depth: 3, path: []int{1, 1, 1}}, nodes[8])
+}
+
+func TestWalkActions(t *testing.T) {
+	t.Parallel()
+
+	e := New("outer").Wrap(Join(New("skipped").Wrap(io.EOF), io.ErrClosedPipe)).Wrap(io.ErrShortBuffer)
+
+	skipped := collect(e, func(node error) WalkAction {
+		if w, ok := node.(Wrappable); ok && w.Err().Error() == "skipped" {
+			return WalkSkip
+		}
+
+		return WalkContinue
+	})
+	for _, node := range skipped {
+		assert.NotEqual(t, "EOF", node.msg)
+	}
+	assert.Equal(t, io.ErrShortBuffer.Error(), skipped[len(skipped)-1].msg)
+
+	stopped := collect(e, func(node error) WalkAction {
+		if node == io.EOF {
+			return WalkStop
+		}
+
+		return WalkContinue
+	})
+	assert.Equal(t, "EOF", stopped[len(stopped)-1].msg)
+	assert.Less(t, len(stopped), len(collect(e, proceed)))
+}
+
+func TestNodes(t *testing.T) {
+	t.Parallel()
+
+	e := New("outer").Wrap(Join(io.EOF, io.ErrClosedPipe))
+
+	var all []Node
+	for node := range Nodes(e) {
+		all = append(all, node)
+	}
+	require.Len(t, all, 5)
+	assert.Equal(t, Node{Err: io.ErrClosedPipe, Depth: 2, Path: []int{1, 1}}, all[4])
+
+	// paths are not shared between nodes
+	assert.Equal(t, []int{1, 0}, all[3].Path)
+
+	for node := range Nodes(e) {
+		if node.Err == io.EOF {
+			break
+		}
+	}
+}
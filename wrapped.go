@@ -9,15 +9,52 @@ import (
 var _ Wrappable = &wrapped{}
 
 // New builds a wrappable error from a string
-func New(msg string) Wrappable {
-	return &wrapped{err: errors.New(msg)}
+func New(msg string, opts ...Option) Wrappable {
+	return newWrapped(newString(msg), opts)
 }
 
 // NewErr builds a wrappable error from another error
-func NewErr(err error) Wrappable {
-	return &wrapped{err: err}
+func NewErr(err error, opts ...Option) Wrappable {
+	return newWrapped(err, opts)
 }
 
+// NewWithCauses builds a wrappable error from a string, with several nested causes.
+//
+// The causes are joined as with Join(). Nil causes are discarded.
+func NewWithCauses(msg string, causes ...error) Wrappable {
+	e := &wrapped{err: newString(msg)}
+
+	cause := Join(causes...)
+	if cause == nil {
+		return e
+	}
+
+	if inner := cause.(*joined).errs; len(inner) == 1 {
+		e.cause = inner[0]
+	} else {
+		e.cause = cause
+	}
+
+	return e
+}
+
+// errorString is the topmost error of the errors built from a string.
+//
+// Unlike errors.New(), it tells apart the errors built by this package from the ones supplied by callers.
+type errorString struct {
+	s string
+}
+
+func newString(msg string) error {
+	return &errorString{s: msg}
+}
+
+func (e *errorString) Error() string {
+	return e.s
+}
+
+func (e *errorString) ownedByPackage() {}
+
 // wrapped produces a stack of errors. It implements the Wrappable interface.
 //
 //
@@ -28,6 +65,20 @@ func NewErr(err error) Wrappable {
 type wrapped struct {
 	err   error
 	cause error
+	attrs []Attr
+
+	// locations of the code which built the topmost error and stacked the cause, when tracked (see WithCaller).
+	// When the cause is a chain, the chain knows the location of every stacked error.
+	trackCaller bool
+	pc          uintptr
+	causePC     uintptr
+
+	// locations of the topmost error and of the latest stacked error, when decoded
+	origin      *Frame
+	causeOrigin *Frame
+
+	// locations this error went through while being returned, when traced (see Trace)
+	trace *returnTrace
 }
 
 type wrappedIface interface {
@@ -35,6 +86,15 @@ type wrappedIface interface {
 	isWrapped()
 }
 
+// headTail is an error which knows how to yield its head and tail, like Wrappable.
+//
+// Custom error types which override the Wrap() method are not Wrappable, but still satisfy this interface.
+type headTail interface {
+	error
+	Err() error
+	Unwrap() error
+}
+
 // Error implements the error interface, with plain formatting.
 // Nested errors are printed, separated by a ":".
 func (e wrapped) Error() string {
@@ -50,7 +110,7 @@ func (e wrapped) Error() string {
 //
 // This is a shorthand for Wrap(fmt.Errorf(format, args...)).
 func (e wrapped) Errorf(format string, args ...interface{}) Wrappable {
-	return e.Wrap(fmt.Errorf(format, args...))
+	return e.wrap(fmt.Errorf(format, args...), 1)
 }
 
 // Wrap another error. Returns a shallow clone.
@@ -60,48 +120,57 @@ func (e wrapped) Errorf(format string, args ...interface{}) Wrappable {
 // errors.Is() and errors.As() methods from the standard library.
 //
 // More generally error stacking supports any other stacking mechanism on underlying errors
-// equipped with the standard Unwrap() error method.
+// equipped with the standard Unwrap() error or Unwrap() []error methods: the cause is retained as is,
+// and err is stacked after it.
+//
+// Wrapping is a constant time operation: the stack of causes is shared with the original error.
 func (e *wrapped) Wrap(err error) Wrappable {
+	return e.wrap(err, 1)
+}
+
+// wrap another error. When the caller is tracked, its location is captured depth frames above wrap.
+func (e *wrapped) wrap(err error, depth int) Wrappable {
 	if err == nil {
 		return e
 	}
 
-	if e.cause == nil {
-		return &wrapped{
-			err:   e.err,
-			cause: err,
-		}
+	reportDeprecated(e.err)
+
+	var pc uintptr
+	trace := propagatedTrace(e.trace, err)
+	if e.trackCaller || trace != nil {
+		pc = callerPC(depth + 1)
 	}
 
-	wrapper, ok := e.cause.(Wrappable)
-	if ok {
-		// stack err at the tail of the cause
-		return &wrapped{
-			err:   e.err,
-			cause: wrapper.Wrap(err),
-		}
+	wrapper := &wrapped{
+		err:         e.err,
+		attrs:       e.attrs,
+		trackCaller: e.trackCaller,
+		pc:          e.pc,
+		origin:      e.origin,
 	}
 
-	unwrapper, ok := e.cause.(interface{ Unwrap() error })
-	if ok {
-		// destructure the cause and wrap err at the tail
-		cause := &wrapped{
-			err:   e.cause,
-			cause: unwrapper.Unwrap(),
-		}
-		return &wrapped{
-			err:   e.err,
-			cause: cause.Wrap(err),
-		}
+	var located uintptr
+	if e.trackCaller {
+		located = pc
 	}
 
-	return &wrapped{
-		err: e.err,
-		cause: &wrapped{
-			err:   e.cause,
-			cause: err,
-		},
+	switch current := e.cause.(type) {
+	case nil:
+		wrapper.cause, wrapper.causePC = err, located
+	case *chain:
+		wrapper.cause = current.append(err, located)
+	default:
+		tail := newChain(current, err)
+		tail.pcs = appendPC(appendPC(nil, 0, e.causePC), 1, located)
+		wrapper.cause = tail
 	}
+
+	if trace != nil {
+		wrapper.trace = trace.append(pc)
+	}
+
+	return wrapper
 }
 
 // Unwrap implements errors.Unwrap: its returns the nested error
@@ -128,7 +197,13 @@ func (e *wrapped) Is(err error) bool {
 		return false
 	}
 
-	if errors.Is(e.err, err) {
+	if matcher, ok := err.(*Matcher); ok {
+		return matcher.Match(e)
+	}
+
+	reportDeprecated(err)
+
+	if errors.Is(e.err, err) || inherits(e.err, err) {
 		return true
 	}
 
@@ -143,9 +218,11 @@ func (e *wrapped) Is(err error) bool {
 
 var errorType = reflect.TypeOf((*error)(nil)).Elem()
 
-// As implements errors.As
+// As implements errors.As.
+//
+// The topmost error is fully explored, including any tree of errors it may hold.
 func (e *wrapped) As(target interface{}) bool {
-	return as(e, target) || as(e.err, target) || as(e.cause, target)
+	return as(e, target) || (e.err != nil && errors.As(e.err, target)) || as(e.cause, target)
 }
 
 func (e wrapped) isWrapped() {}